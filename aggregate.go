@@ -0,0 +1,77 @@
+package winroute
+
+import (
+	"net/netip"
+
+	"github.com/bnkrr/winroute/internal/aggregate"
+)
+
+// Aggregation 是 SuggestAggregations 给出的一条合并建议：Routes 中的路由可以用一条
+// 目标为 Supernet、下一跳、接口和 Metric 不变的路由代替。
+type Aggregation struct {
+	Supernet  netip.Prefix
+	NextHop   netip.Addr
+	Interface *Interface
+	Metric    uint32
+	Routes    []*Route // 被代替的路由，按路由表顺序排列
+}
+
+// aggregationKey 是可以合并的路由必须相同的属性。
+type aggregationKey struct {
+	nextHop    netip.Addr
+	ifaceIndex uint32
+	metric     uint32
+}
+
+// SuggestAggregations 找出可以合并为更短前缀的路由，只给出建议，不修改路由表。
+//
+// 匹配 filters 的路由按 (NextHop, Interface, Metric) 分组，组内两种情况可以合并：
+// 相邻的前缀（例如 10.0.0.0/24 和 10.0.1.0/24 合并为 10.0.0.0/23，可以逐级合并），
+// 以及被组内另一条路由覆盖的前缀（例如 10.0.0.0/8 已经包含 10.1.0.0/16）。
+// 结果按分组在路由表中首次出现的顺序排列，同组内按 Supernet 排序。
+//
+// 合并只考虑组内的路由：如果其他路由（例如经由另一网关的 10.0.0.0/23）与 Supernet 重叠，
+// 合并后最长前缀匹配的结果可能改变。VPN 常用 0.0.0.0/1 和 128.0.0.0/1 覆盖默认路由，
+// 它们会被建议合并为 0.0.0.0/0，这通常不是想要的结果。应用建议前请检查；
+// 清理时一般还应传入 WithoutSystemRoutes。
+func SuggestAggregations(filters ...FilterOption) ([]Aggregation, error) {
+	routes, err := GetRoutes(filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []aggregationKey
+	groups := make(map[aggregationKey][]*Route)
+	for _, route := range routes {
+		key := aggregationKey{route.NextHop.WithZone(""), route.Interface.Index, route.Metric}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], route)
+	}
+
+	var suggestions []Aggregation
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		prefixes := make([]netip.Prefix, len(group))
+		for i, route := range group {
+			prefixes[i] = route.Destination
+		}
+		for _, merged := range aggregate.Merge(prefixes) {
+			aggregation := Aggregation{
+				Supernet:  merged.Supernet,
+				NextHop:   group[0].NextHop,
+				Interface: group[0].Interface,
+				Metric:    key.metric,
+			}
+			for _, i := range merged.Members {
+				aggregation.Routes = append(aggregation.Routes, group[i])
+			}
+			suggestions = append(suggestions, aggregation)
+		}
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,94 @@
+package winroute
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bnkrr/winroute/internal/notes"
+)
+
+// ---- 路由注释：保存在 JSON 文件中的路由说明 ----
+
+// DefaultAnnotationPath 返回默认的路由注释文件路径：当前用户配置目录下的
+// winroute\notes.json（通常为 %AppData%\winroute\notes.json）。
+func DefaultAnnotationPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "winroute", "notes.json"), nil
+}
+
+// SetAnnotationPath 设置 Annotate 和 Annotations 使用的注释文件路径。
+// 未设置或设置为空字符串时使用 DefaultAnnotationPath。
+func (t *RouteTable) SetAnnotationPath(path string) {
+	t.notesMu.Lock()
+	defer t.notesMu.Unlock()
+	t.notesPath = path
+}
+
+// annotationPath 返回当前使用的注释文件路径。调用方必须持有 notesMu。
+func (t *RouteTable) annotationPath() (string, error) {
+	if t.notesPath != "" {
+		return t.notesPath, nil
+	}
+	return DefaultAnnotationPath()
+}
+
+// noteKey 返回路由在注释文件中的键：目标、去除 zone 的下一跳和接口索引。
+func noteKey(route *Route) notes.Key {
+	return notes.Key{
+		Destination:    route.Destination,
+		NextHop:        route.NextHop.WithZone(""),
+		InterfaceIndex: route.Interface.Index,
+	}
+}
+
+// Annotate 为路由记录一条说明（例如添加它的原因），note 为空字符串时删除已有的说明。
+//
+// Windows 路由无法携带自定义数据，因此说明保存在单独的 JSON 文件中（见 SetAnnotationPath），
+// 以目标、下一跳和接口索引作为键。说明不会随路由一起删除；接口索引改变后原有说明也不再匹配。
+func (t *RouteTable) Annotate(route *Route, note string) error {
+	t.notesMu.Lock()
+	defer t.notesMu.Unlock()
+
+	path, err := t.annotationPath()
+	if err != nil {
+		return err
+	}
+	stored, err := notes.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load route notes: %w", err)
+	}
+	if note == "" {
+		delete(stored, noteKey(route))
+	} else {
+		stored[noteKey(route)] = note
+	}
+	if err := notes.Save(path, stored); err != nil {
+		return fmt.Errorf("failed to save route notes: %w", err)
+	}
+	return nil
+}
+
+// Annotations 返回 routes 中每条路由的说明，顺序与 routes 一致；没有说明的路由对应空字符串。
+// 注释文件不存在时所有说明均为空。
+func (t *RouteTable) Annotations(routes []*Route) ([]string, error) {
+	t.notesMu.Lock()
+	defer t.notesMu.Unlock()
+
+	path, err := t.annotationPath()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := notes.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load route notes: %w", err)
+	}
+	result := make([]string, len(routes))
+	for i, route := range routes {
+		result[i] = stored[noteKey(route)]
+	}
+	return result, nil
+}
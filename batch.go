@@ -0,0 +1,84 @@
+package winroute
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bnkrr/winroute/internal/routeops"
+)
+
+// ---- BatchResult: 批量操作的结果汇总 ----
+
+// RouteError 记录批量操作中一条路由的失败。批量操作返回的 partialErrs 中的每个错误
+// 以及 BatchResult.Errors 都是 *RouteError，可以用 errors.As 取出。字段：
+//   - Op：操作类型，"add"、"delete" 或 "update"
+//   - Route：失败路由的描述，例如 "10.0.0.0/8 via 192.168.1.1 dev Ethernet(5) metric 10 [NetMgmt]"
+//   - Err：底层错误，可以用 errors.Is 判断，例如 ErrAccessDenied
+type RouteError = routeops.OpError
+
+// BatchResult 汇总批量操作（DeleteRoutesBatch、AddRoutesBatch）中每条路由的结果。
+//
+// BatchResult 实现了 error 接口，存在失败时可以直接作为错误返回；
+// errors.Is 和 errors.As 会依次检查其中的每个 RouteError。
+type BatchResult struct {
+	Succeeded int
+	Failed    int
+	Errors    []*RouteError
+}
+
+// HasErrors 报告是否有路由操作失败。
+func (r *BatchResult) HasErrors() bool {
+	return r.Failed > 0
+}
+
+// Error 返回失败数量以及每个失败的描述。
+func (r *BatchResult) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d route operations failed", r.Failed, r.Succeeded+r.Failed)
+	for i, err := range r.Errors {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap 返回所有失败，供 errors.Is 和 errors.As 使用。
+func (r *BatchResult) Unwrap() []error {
+	errs := make([]error, len(r.Errors))
+	for i, err := range r.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// countSucceeded 包装批量操作中的单路由操作，使每次成功都计入 result.Succeeded。
+func countSucceeded[T any](result *BatchResult, fn func(T) error) func(T) error {
+	return func(item T) error {
+		if err := fn(item); err != nil {
+			return err
+		}
+		result.Succeeded++
+		return nil
+	}
+}
+
+// collect 将 routeops 返回的失败（继续模式下的 partialErrs，或停止模式下的第一个错误）记入结果。
+func (r *BatchResult) collect(partialErrs []error, stopErr error) {
+	if stopErr != nil {
+		partialErrs = append(partialErrs, stopErr)
+	}
+	for _, err := range partialErrs {
+		var routeErr *RouteError
+		if errors.As(err, &routeErr) {
+			r.Errors = append(r.Errors, routeErr)
+		} else {
+			r.Errors = append(r.Errors, &RouteError{Err: err})
+		}
+	}
+	r.Failed = len(r.Errors)
+}
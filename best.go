@@ -0,0 +1,200 @@
+package winroute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/bnkrr/winroute/internal/bestmatch"
+	"github.com/bnkrr/winroute/internal/poll"
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// FindBestRoute 对 addr 执行最长前缀匹配，返回系统当前用于到达 addr 的路由。
+// 与 Windows 的选择规则相同，只考虑处于运行状态的接口上的路由，前缀长度相同时选择
+// 路由 Metric 与接口 metric 之和最小的路由。找不到可用路由时返回 ErrNotFound。
+//
+// 注意：这是根据路由表做出的推断，不考虑源地址等其他因素；需要系统实际的选择时使用 ResolveOutbound。
+func FindBestRoute(addr netip.Addr) (*Route, error) {
+	routes, err := GetRoutes()
+	if err != nil {
+		return nil, err
+	}
+	up := routes[:0]
+	for _, route := range routes {
+		if route.Interface.IsUp() {
+			up = append(up, route)
+		}
+	}
+	return selectBestRoute(up, addr)
+}
+
+// selectBestRoute 在给定的路由列表中为 addr 选择最佳路由：最长前缀匹配，前缀长度相同时比较
+// 路由 Metric 与接口 metric 之和。IPv4 映射的 IPv6 地址按 IPv4 地址查找。
+func selectBestRoute(routes []*Route, addr netip.Addr) (*Route, error) {
+	addr = addr.Unmap()
+	best, ok := bestmatch.Select(
+		routes,
+		addr,
+		func(r *Route) netip.Prefix { return r.Destination },
+		(*Route).effectiveMetric,
+	)
+	if !ok {
+		return nil, fmt.Errorf("no route to %s: %w", addr, ErrNotFound)
+	}
+	return best, nil
+}
+
+// GetInterfaceForDestination 返回系统用于到达 addr 的出接口（类似 Linux 上的 ip route get），
+// 规则与 FindBestRoute 相同。找不到可用路由时返回 ErrNotFound。
+func GetInterfaceForDestination(addr netip.Addr) (*Interface, error) {
+	route, err := FindBestRoute(addr)
+	if err != nil {
+		return nil, err
+	}
+	return route.Interface, nil
+}
+
+// GatewayFor 返回系统当前用于到达 addr 的下一跳和出接口，由系统通过 ResolveOutbound（GetBestRoute2）选出。
+// 适用于“经由同一网关添加一条更具体的路由”的场景。addr 位于直连网段时，
+// 返回的下一跳是未指定地址（0.0.0.0 或 ::），表示直接发送到链路上。
+// addr 的处理方式与 ResolveOutbound 相同；找不到可用路由时返回 ErrNotFound。
+func GatewayFor(addr netip.Addr) (netip.Addr, *Interface, error) {
+	_, route, err := ResolveOutbound(addr)
+	if err != nil {
+		return netip.Addr{}, nil, err
+	}
+	return route.NextHop, route.Interface, nil
+}
+
+// PrimaryInterface 返回承载最佳 IPv4 默认路由的接口，通常就是连接互联网的主网卡。
+// 与 Windows 的选择规则相同，默认路由按路由 Metric 与接口 IPv4 metric 之和比较，取最小者；
+// 这一点很重要，因为 DHCP 安装的默认路由的路由 Metric 通常都是 0。
+// 只考虑处于运行状态的接口；没有可用的默认路由时返回 ErrNotFound。
+func PrimaryInterface() (*Interface, error) {
+	best, err := bestDefaultRoute()
+	if err != nil {
+		return nil, err
+	}
+	return best.Interface, nil
+}
+
+// PickFastestInterface 返回处于运行状态的接口中链路速度最快的一个，用于为路由选择出接口。
+// 按 TransmitSpeed 比较，相同时比较 ReceiveSpeed，仍相同时取系统返回顺序中靠前的接口。
+// 环回接口和速度未知（为 0）的接口不参与比较；没有符合条件的接口时返回 ErrNotFound。
+//
+// 链路速度由驱动报告，虚拟网卡（例如部分 VPN 和虚拟机网卡）报告的通常是名义值，
+// 不代表实际可用带宽。
+func PickFastestInterface() (*Interface, error) {
+	ifaces, err := ListActiveInterfaces()
+	if err != nil {
+		return nil, err
+	}
+	var fastest *Interface
+	for _, iface := range ifaces {
+		if iface.IfType == winapi.IfTypeSoftwareLoopback || iface.TransmitSpeed == 0 {
+			continue
+		}
+		if fastest == nil || iface.TransmitSpeed > fastest.TransmitSpeed ||
+			(iface.TransmitSpeed == fastest.TransmitSpeed && iface.ReceiveSpeed > fastest.ReceiveSpeed) {
+			fastest = iface
+		}
+	}
+	if fastest == nil {
+		return nil, fmt.Errorf("no active interface with a known link speed: %w", ErrNotFound)
+	}
+	return fastest, nil
+}
+
+// bestDefaultRoute 返回运行状态的接口上路由 Metric 与接口 metric 之和最小的 IPv4 默认路由，
+// 没有时返回 ErrNotFound。
+func bestDefaultRoute() (*Route, error) {
+	defaultRoute := netip.PrefixFrom(netip.IPv4Unspecified(), 0)
+	routes, err := GetRoutes(WithDestinationPrefix(defaultRoute))
+	if err != nil {
+		return nil, err
+	}
+
+	up := routes[:0]
+	for _, route := range routes {
+		if route.Interface.IsUp() {
+			up = append(up, route)
+		}
+	}
+	if len(up) == 0 {
+		return nil, fmt.Errorf("no IPv4 default route: %w", ErrNotFound)
+	}
+	best, _ := bestmatch.Select(
+		up,
+		netip.IPv4Unspecified(),
+		func(r *Route) netip.Prefix { return r.Destination },
+		(*Route).effectiveMetric,
+	)
+	return best, nil
+}
+
+// defaultRoutePollInterval 是 WaitForDefaultRoute 轮询路由表的间隔。
+const defaultRoutePollInterval = 500 * time.Millisecond
+
+// WaitForDefaultRoute 阻塞直到运行状态的接口上出现 IPv4 默认路由，并返回其中最佳的一条
+// （与 PrimaryInterface 的选择规则相同），可用于启动脚本中等待网络就绪。
+// 默认路由已经存在时立即返回。
+//
+// 实现方式是定期轮询路由表（每次都会重新读取接口状态），ctx 结束时返回 ctx.Err()。
+// 查询路由表本身出错时立即返回该错误。
+func WaitForDefaultRoute(ctx context.Context) (*Route, error) {
+	var route *Route
+	err := poll.Until(ctx, defaultRoutePollInterval, func() (bool, error) {
+		best, err := bestDefaultRoute()
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		route = best
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return route, nil
+}
+
+// ResolveOutbound 通过 GetBestRoute2 询问系统发往 destination 时实际使用的路由和源地址。
+// 与 FindBestRoute 的手工最长前缀匹配不同，它由系统完成选择：比较路由 metric 与接口 metric 之和，
+// 跳过未运行的接口，并按 RFC 6724 的源地址选择规则选出源地址，因此与真实连接的行为一致。
+//
+// IPv4 映射的 IPv6 地址按 IPv4 地址查询。IPv6 链路本地地址需要 zone（接口索引或别名，
+// 例如 fe80::1%5 或 fe80::1%以太网）指明出接口。没有可用路由时返回 ErrNotFound。
+func ResolveOutbound(destination netip.Addr) (source netip.Addr, route *Route, err error) {
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return netip.Addr{}, nil, err
+	}
+	destination = destination.Unmap()
+	if zone := destination.Zone(); zone != "" {
+		iface, err := cache.resolveInterface(zone)
+		if err != nil {
+			return netip.Addr{}, nil, fmt.Errorf("zone of %s: %w", destination, err)
+		}
+		destination = destination.WithZone(strconv.FormatUint(uint64(iface.Index), 10))
+	}
+
+	row, source, err := provider.bestRoute(destination)
+	if err != nil {
+		if errors.Is(err, winapi.ERROR_NETWORK_UNREACHABLE) || errors.Is(err, winapi.ERROR_NOT_FOUND) {
+			return netip.Addr{}, nil, fmt.Errorf("no route to %s: %w", destination, ErrNotFound)
+		}
+		return netip.Addr{}, nil, fmt.Errorf("failed to resolve route to %s: %w", destination, err)
+	}
+	iface, ok := cache.byLUID[row.InterfaceLUID]
+	if !ok {
+		return netip.Addr{}, nil, fmt.Errorf("interface of the route to %s not found: %w", destination, ErrNotFound)
+	}
+	r := newRoute(row, iface)
+	return source.WithZone(""), &r, nil
+}
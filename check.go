@@ -0,0 +1,184 @@
+package winroute
+
+import (
+	"net/netip"
+
+	"github.com/bnkrr/winroute/internal/loopcheck"
+	"github.com/bnkrr/winroute/internal/routecheck"
+)
+
+// RouteIssueKind 表示 ValidateRoutes 发现的问题类型。
+type RouteIssueKind int
+
+const (
+	// IssueDuplicateRoute 表示存在目标、下一跳和接口都相同的重复路由。
+	IssueDuplicateRoute = RouteIssueKind(routecheck.KindDuplicate)
+	// IssueInterfaceDown 表示路由使用的接口未处于运行状态。
+	IssueInterfaceDown = RouteIssueKind(routecheck.KindInterfaceDown)
+	// IssueDefaultRouteTie 表示同一地址族存在多条 metric 相同的默认路由。
+	IssueDefaultRouteTie = RouteIssueKind(routecheck.KindDefaultRouteTie)
+	// IssueNextHopUnreachable 表示路由的下一跳不在其接口的链路前缀内。
+	IssueNextHopUnreachable = RouteIssueKind(routecheck.KindNextHopUnreachable)
+)
+
+// String 返回问题类型的简短名称。
+func (k RouteIssueKind) String() string {
+	switch k {
+	case IssueDuplicateRoute:
+		return "duplicate"
+	case IssueInterfaceDown:
+		return "interface-down"
+	case IssueDefaultRouteTie:
+		return "default-route-tie"
+	case IssueNextHopUnreachable:
+		return "next-hop-unreachable"
+	default:
+		return "unknown"
+	}
+}
+
+// RouteIssue 描述路由表中的一个问题。
+type RouteIssue struct {
+	Kind        RouteIssueKind
+	Routes      []*Route // 与该问题相关的路由
+	Description string   // 人类可读的问题描述
+}
+
+// ValidateRoutes 扫描系统路由表并报告可能的问题：
+//   - 重复路由（目标、下一跳、接口都相同）；
+//   - 使用未运行接口的路由；
+//   - 同一地址族中 metric 相同的多条默认路由；
+//   - 下一跳不在接口链路前缀内（不可直达）的路由。
+//
+// 系统路由（见 Route.IsSystemRoute）不参与接口状态和下一跳检查。
+func ValidateRoutes() ([]RouteIssue, error) {
+	routes, err := GetRoutes()
+	if err != nil {
+		return nil, err
+	}
+	return validateRoutes(routes), nil
+}
+
+func validateRoutes(routes []*Route) []RouteIssue {
+	entries := make([]routecheck.Entry, len(routes))
+	for i, r := range routes {
+		entries[i] = routecheck.Entry{
+			Destination:        r.Destination,
+			NextHop:            r.NextHop,
+			Metric:             r.Metric,
+			InterfaceIndex:     r.Interface.Index,
+			InterfaceUp:        r.Interface.IsUp(),
+			InterfaceAddresses: r.Interface.Addresses,
+			System:             r.IsSystemRoute(),
+		}
+	}
+
+	found := routecheck.Check(entries)
+	issues := make([]RouteIssue, 0, len(found))
+	for _, issue := range found {
+		affected := make([]*Route, len(issue.Entries))
+		for i, index := range issue.Entries {
+			affected[i] = routes[index]
+		}
+		issues = append(issues, RouteIssue{
+			Kind:        RouteIssueKind(issue.Kind),
+			Routes:      affected,
+			Description: issue.Description,
+		})
+	}
+	return issues
+}
+
+// FindConflictingRoutes 返回目标前缀与 destination 完全相同的所有路由，不论其接口和下一跳。
+// 添加路由前可以用它检查同一目标是否已经存在经由其他接口的路由，以免意外形成多路径。
+// destination 中的主机位会先被清除，与 AddRoute 的规范化方式一致。
+func FindConflictingRoutes(destination netip.Prefix) ([]*Route, error) {
+	destination, _ = normalizeDestination(destination)
+	return GetRoutes(WithDestinationPrefix(destination))
+}
+
+// maxNextHopDepth 是 DetectRoutingLoops 跟踪下一跳解析链的最大深度。
+const maxNextHopDepth = 32
+
+// DetectRoutingLoops 检测下一跳解析中的环路。
+//
+// 对每条带网关的路由，它按最长前缀、其次路由 Metric 与接口 metric 之和最小的规则解析其下一跳所用的路由
+// （与 FindBestRoute 相同，但也考虑未运行的接口上的路由），
+// 再继续解析那条路由的下一跳，直到遇到直连路由、无法解析或超过深度上限。
+// 如果解析链回到了链上已经出现过的路由，就报告这个环路：每个环路按解析顺序列出其中的路由，且只报告一次。
+// 例如 10.0.0.0/8 经由 10.1.1.1 的路由会解析回自身，形成长度为 1 的环路。
+func DetectRoutingLoops() ([][]*Route, error) {
+	routes, err := GetRoutes()
+	if err != nil {
+		return nil, err
+	}
+	return loopcheck.Find(
+		routes,
+		func(r *Route) (*Route, bool) {
+			if !routecheck.HasGateway(r.NextHop) {
+				return nil, false
+			}
+			next, err := selectBestRoute(routes, r.NextHop)
+			return next, err == nil
+		},
+		identityOf,
+		maxNextHopDepth,
+	), nil
+}
+
+// RoutesDependingOnInterface 返回删除或停用 identifier（接口索引或别名，规则同 ResolveInterfaces）
+// 指定的接口后会失效的路由，按路由表顺序排列：
+//   - 使用该接口作为出接口的所有路由（即 WithInterfaceIndex 的结果）；
+//   - 其他接口上的网关路由，其下一跳不在自身出接口的直连网段内，当前按 DetectRoutingLoops 的规则经由该接口到达，
+//     且去掉该接口上的路由后再没有其他路由可以到达。
+//
+// 别名匹配多个接口时返回 ErrAmbiguousMatch，接口不存在时返回 ErrNotFound。
+func RoutesDependingOnInterface(identifier string) ([]*Route, error) {
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return nil, err
+	}
+	iface, err := cache.resolveInterface(identifier)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := getRoutes(cache, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var others []*Route
+	for _, route := range routes {
+		if route.Interface.Index != iface.Index {
+			others = append(others, route)
+		}
+	}
+
+	var dependent []*Route
+	for _, route := range routes {
+		if route.Interface.Index == iface.Index || dependsOnInterface(route, iface, routes, others) {
+			dependent = append(dependent, route)
+		}
+	}
+	return dependent, nil
+}
+
+// dependsOnInterface 判断另一接口上的路由 route 的下一跳是否只能经由 iface 到达。
+// others 是不经过 iface 的路由；解析下一跳时不考虑 route 自身（例如覆盖自己网关的默认路由）。
+func dependsOnInterface(route *Route, iface *Interface, routes, others []*Route) bool {
+	if !routecheck.HasGateway(route.NextHop) || routecheck.OnLink(route.NextHop, route.Interface.Addresses) {
+		return false
+	}
+	best, err := selectBestRoute(routes, route.NextHop)
+	if err != nil || best.Interface.Index != iface.Index {
+		return false
+	}
+	alternatives := make([]*Route, 0, len(others))
+	for _, other := range others {
+		if other != route {
+			alternatives = append(alternatives, other)
+		}
+	}
+	_, err = selectBestRoute(alternatives, route.NextHop)
+	return err != nil
+}
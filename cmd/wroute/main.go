@@ -3,11 +3,16 @@
 package main
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/netip"
 	"os"
+	"slices"
+	"strconv"
 	"text/tabwriter"
 
 	"github.com/bnkrr/winroute"
@@ -41,87 +46,327 @@ func main() {
 	Execute()
 }
 
+// requireElevation fails early with a clear message when the process lacks
+// Administrator privileges. If elevation cannot be determined, the command
+// proceeds and any permission problem surfaces from the operation itself.
+func requireElevation(cmd *cobra.Command, args []string) error {
+	elevated, err := winroute.IsElevated()
+	if err != nil || elevated {
+		return nil
+	}
+	return fmt.Errorf("'%s' requires Administrator privileges; run wroute from an elevated prompt", cmd.Name())
+}
+
+// printWarnings reports non-fatal library warnings on stderr.
+func printWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintln(stderr, "warning:", warning)
+	}
+}
+
 // ---- getCmd ----
 var getCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Get and filter Windows routes",
 	Long:  `Retrieves the system's routing table. You can apply filters to narrow down the results.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var filters []winroute.FilterOption
-
-		// Destination Prefix Filter
-		if destStr, _ := cmd.Flags().GetString("destination"); destStr != "" {
-			prefix, err := netip.ParsePrefix(destStr)
-			if err != nil {
-				return fmt.Errorf("invalid destination prefix '%s': %w", destStr, err)
-			}
-			filters = append(filters, winroute.WithDestinationPrefix(prefix))
+		output, _ := cmd.Flags().GetString("output")
+		if output != outputTable && output != outputJSON && output != outputCSV && output != outputRoutePrint {
+			return fmt.Errorf("invalid output format '%s': must be one of %s, %s, %s, %s", output, outputTable, outputJSON, outputCSV, outputRoutePrint)
 		}
 
-		// Interface Index Filter
-		if ifIndex, _ := cmd.Flags().GetUint32("if-index"); ifIndex > 0 {
-			filters = append(filters, winroute.WithInterfaceIndex(ifIndex))
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		if groupBy != "" && groupBy != groupByInterface {
+			return fmt.Errorf("invalid group-by '%s': must be %s", groupBy, groupByInterface)
+		}
+		if groupBy != "" && output != outputTable {
+			return fmt.Errorf("--group-by is only supported with the %s output format", outputTable)
 		}
 
-		// Interface Alias Filter
-		if ifAlias, _ := cmd.Flags().GetString("if-alias"); ifAlias != "" {
-			filters = append(filters, winroute.WithInterfaceAlias(ifAlias))
+		filters, err := filtersFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("limit") {
+			limit, _ := cmd.Flags().GetInt("limit")
+			filters = append(filters, winroute.Limit(limit))
 		}
 
-		// Metric Filter
-		if cmd.Flags().Changed("metric") {
-			metric, _ := cmd.Flags().GetUint32("metric")
-			filters = append(filters, winroute.WithMetric(metric))
+		if groupBy == groupByInterface {
+			groups, err := winroute.GetRoutesGroupedByInterface(append(filters, winroute.Deduplicate())...)
+			if err != nil {
+				return fmt.Errorf("failed to get routes: %w", err)
+			}
+			if len(groups) == 0 {
+				fmt.Println("No routes found matching the criteria.")
+				return nil
+			}
+			return printRouteGroups(os.Stdout, groups)
 		}
 
-		routes, err := winroute.GetRoutes(filters...)
+		table, err := winroute.NewRouteTable()
+		if err != nil {
+			return fmt.Errorf("failed to get routes: %w", err)
+		}
+		routes, err := table.GetRoutes(append(filters, winroute.Deduplicate())...)
 		if err != nil {
 			return fmt.Errorf("failed to get routes: %w", err)
 		}
 
+		switch output {
+		case outputJSON:
+			return printRoutesJSON(os.Stdout, routes)
+		case outputCSV:
+			return printRoutesCSV(os.Stdout, routes)
+		case outputRoutePrint:
+			return printRoutesRoutePrint(os.Stdout, routes)
+		}
+
 		if len(routes) == 0 {
 			fmt.Println("No routes found matching the criteria.")
 			return nil
 		}
 
-		// Print results in a table
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-		fmt.Fprintln(w, "DESTINATION\tNEXT_HOP\tMETRIC\tIFACE_INDEX\tIFACE_ALIAS")
-		for _, route := range routes {
-			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n",
-				route.Destination,
-				route.NextHop,
-				route.Metric,
-				route.Interface.Index,
-				route.Interface.Alias,
-			)
+		// Notes are optional decoration; an unreadable notes file should not hide the routes.
+		setNotesFile(cmd, table)
+		notes, err := table.Annotations(routes)
+		if err != nil {
+			printWarnings([]string{err.Error()})
 		}
-		return w.Flush()
+		return printRoutesTable(os.Stdout, routes, notes)
 	},
 }
 
+// ---- output formats ----
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+	// outputRoutePrint mimics the layout of the built-in "route print" command.
+	outputRoutePrint = "route-print"
+)
+
+// printRoutesTable prints routes as an aligned table. notes, when not nil, holds
+// one note per route; the NOTE column is only shown if at least one is set.
+func printRoutesTable(out io.Writer, routes []*winroute.Route, notes []string) error {
+	showNotes := slices.ContainsFunc(notes, func(note string) bool { return note != "" })
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	header := "DESTINATION\tNEXT_HOP\tMETRIC\tIFACE_INDEX\tIFACE_ALIAS"
+	if showNotes {
+		header += "\tNOTE"
+	}
+	fmt.Fprintln(w, header)
+	for i, route := range routes {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s",
+			route.Destination,
+			route.NextHop,
+			route.Metric,
+			route.Interface.Index,
+			route.Interface.Alias,
+		)
+		if showNotes {
+			fmt.Fprintf(w, "\t%s", notes[i])
+		}
+		fmt.Fprintln(w)
+	}
+	return w.Flush()
+}
+
+const groupByInterface = "interface"
+
+// printRouteGroups prints one table per interface, ordered by interface index.
+func printRouteGroups(out io.Writer, groups map[uint32][]*winroute.Route) error {
+	indices := make([]uint32, 0, len(groups))
+	for index := range groups {
+		indices = append(indices, index)
+	}
+	slices.Sort(indices)
+
+	for i, index := range indices {
+		routes := groups[index]
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "Interface %d (%s): %d routes\n", index, routes[0].Interface.Alias, len(routes))
+		if err := printRoutesTable(out, routes, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printRoutesRoutePrint prints routes in the layout of "route print": an IPv4
+// table with dotted netmasks followed by an IPv6 table, each sorted by destination.
+// Gateways of on-link routes are shown as "On-link", and IPv4 routes name their
+// interface by its local address as route print does.
+func printRoutesRoutePrint(out io.Writer, routes []*winroute.Route) error {
+	sorted := slices.Clone(routes)
+	slices.SortStableFunc(sorted, func(a, b *winroute.Route) int {
+		if c := a.Destination.Addr().Compare(b.Destination.Addr()); c != 0 {
+			return c
+		}
+		return a.Destination.Bits() - b.Destination.Bits()
+	})
+
+	const rule = "==========================================================================="
+	gateway := func(route *winroute.Route) string {
+		if !route.NextHop.IsValid() || route.NextHop.IsUnspecified() {
+			return "On-link"
+		}
+		return route.NextHop.String()
+	}
+
+	fmt.Fprintln(out, "IPv4 Route Table")
+	fmt.Fprintln(out, rule)
+	fmt.Fprintln(out, "Active Routes:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Network Destination\tNetmask\tGateway\tInterface\tMetric\t")
+	for _, route := range sorted {
+		if !route.Destination.Addr().Is4() {
+			continue
+		}
+		iface := strconv.FormatUint(uint64(route.Interface.Index), 10)
+		if route.PreferredSource.IsValid() {
+			iface = route.PreferredSource.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t\n",
+			route.Destination.Addr(),
+			winroute.NetmaskString(route.Destination),
+			gateway(route),
+			iface,
+			route.Metric,
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, rule)
+	fmt.Fprintln(out)
+
+	fmt.Fprintln(out, "IPv6 Route Table")
+	fmt.Fprintln(out, rule)
+	fmt.Fprintln(out, "Active Routes:")
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, " If Metric\tNetwork Destination\tGateway")
+	for _, route := range sorted {
+		if route.Destination.Addr().Is4() {
+			continue
+		}
+		fmt.Fprintf(w, "%3d %6d\t%s\t%s\n",
+			route.Interface.Index,
+			route.Metric,
+			route.Destination,
+			gateway(route),
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, rule)
+	return nil
+}
+
+func printRoutesJSON(out io.Writer, routes []*winroute.Route) error {
+	data, err := winroute.MarshalRoutes(routes)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err = buf.WriteTo(out)
+	return err
+}
+
+// printRoutesCSV prints routes as CSV with the same columns, in the same order,
+// as the JSON objects produced by Route.MarshalJSON.
+func printRoutesCSV(out io.Writer, routes []*winroute.Route) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{
+		"destination",
+		"next_hop",
+		"metric",
+		"interface_index",
+		"interface_alias",
+		"interface_description",
+		"protocol",
+		"origin",
+	})
+	for _, route := range routes {
+		w.Write([]string{
+			route.Destination.String(),
+			route.NextHop.String(),
+			strconv.FormatUint(uint64(route.Metric), 10),
+			strconv.FormatUint(uint64(route.Interface.Index), 10),
+			route.Interface.Alias,
+			route.Interface.Description,
+			strconv.FormatUint(uint64(route.Protocol), 10),
+			strconv.FormatUint(uint64(route.Origin), 10),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
 // ---- addCmd ----
 var addCmd = &cobra.Command{
-	Use:   "add",
-	Short: "Add a new route",
-	Long:  `Adds a new, non-persistent route to the Windows routing table.`,
+	Use:     "add",
+	Short:   "Add a new route",
+	Long:    `Adds a new, non-persistent route to the Windows routing table.`,
+	PreRunE: requireElevation,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		destStr, _ := cmd.Flags().GetString("destination")
 		nextHopStr, _ := cmd.Flags().GetString("next-hop")
 		ifIndex, _ := cmd.Flags().GetUint32("if-index")
 		metric, _ := cmd.Flags().GetUint32("metric")
 
-		destination, err := netip.ParsePrefix(destStr)
+		destination, err := parseDestination(cmd)
 		if err != nil {
-			return fmt.Errorf("invalid destination prefix '%s': %w", destStr, err)
+			return err
 		}
 
-		nextHop, err := netip.ParseAddr(nextHopStr)
-		if err != nil {
-			return fmt.Errorf("invalid next-hop address '%s': %w", nextHopStr, err)
+		// Without --next-hop the route is on-link: traffic goes straight out the interface.
+		var nextHop netip.Addr
+		if nextHopStr != "" {
+			nextHop, err = netip.ParseAddr(nextHopStr)
+			if err != nil {
+				return fmt.Errorf("invalid next-hop address '%s': %w", nextHopStr, err)
+			}
 		}
 
-		err = winroute.AddRoute(destination, nextHop, ifIndex, metric)
+		// An explicit metric 0 adds no offset of its own, exactly like omitting --metric;
+		// users who pass it usually expect a low priority, so point them at the recommended metric.
+		if cmd.Flags().Changed("metric") && metric == 0 {
+			warning := "metric 0 adds no route offset; the route's effective metric is the interface metric"
+			if recommended, err := winroute.RecommendedMetric(ifIndex); err == nil {
+				warning += fmt.Sprintf("; use --metric %d to rank it behind routes the system installed on this interface", recommended)
+			}
+			printWarnings([]string{warning})
+		}
+
+		// A route on a down adapter is created but carries no traffic until the adapter comes up.
+		if iface, err := winroute.FindInterfaceByIndex(ifIndex); err == nil && !iface.IsUp() {
+			printWarnings([]string{fmt.Sprintf("interface %d (%s) is not up; the route will not carry traffic until it is", iface.Index, iface.Alias)})
+		}
+
+		// Routes to the same destination on other interfaces make the path ambiguous.
+		if conflicts, err := winroute.FindConflictingRoutes(destination); err == nil {
+			for _, route := range conflicts {
+				if route.Interface.Index != ifIndex {
+					printWarnings([]string{fmt.Sprintf("%s already has a route on another interface: %s", destination.Masked(), route)})
+				}
+			}
+		}
+
+		warnings, err := winroute.AddRouteSpecWarn(winroute.RouteSpec{
+			Destination:    destination,
+			NextHop:        nextHop,
+			InterfaceIndex: ifIndex,
+			Metric:         metric,
+		})
+		printWarnings(warnings)
 		if err != nil {
 			return err
 		}
@@ -132,17 +377,17 @@ var addCmd = &cobra.Command{
 
 // ---- deleteRouteCmd ----
 var deleteRouteCmd = &cobra.Command{
-	Use:   "delete-one",
-	Short: "Delete a single, specific route",
-	Long:  `Deletes a single route by precisely matching its destination, next hop, and interface index.`,
+	Use:     "delete-one",
+	Short:   "Delete a single, specific route",
+	Long:    `Deletes a single route by precisely matching its destination, next hop, and interface index.`,
+	PreRunE: requireElevation,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		destStr, _ := cmd.Flags().GetString("destination")
 		nextHopStr, _ := cmd.Flags().GetString("next-hop")
 		ifIndex, _ := cmd.Flags().GetUint32("if-index")
 
-		destination, err := netip.ParsePrefix(destStr)
+		destination, err := parseDestination(cmd)
 		if err != nil {
-			return fmt.Errorf("invalid destination prefix '%s': %w", destStr, err)
+			return err
 		}
 
 		nextHop, err := netip.ParseAddr(nextHopStr)
@@ -151,7 +396,8 @@ var deleteRouteCmd = &cobra.Command{
 		}
 
 		// This calls the specific DeleteRoute function, not the filter-based one.
-		err = winroute.DeleteRoute(destination, nextHop, ifIndex)
+		warnings, err := winroute.DeleteRouteWarn(destination, nextHop, ifIndex)
+		printWarnings(warnings)
 		if err != nil {
 			return err
 		}
@@ -165,61 +411,611 @@ var deleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete routes based on filters",
 	Long: `Deletes one or more routes from the routing table based on the provided filters.
-At least one filter must be specified to prevent accidental deletion of all routes.`,
+At least one filter must be specified to prevent accidental deletion of all routes.
+System routes (loopback, multicast, broadcast and link-local) are skipped unless --include-system is set.`,
+	PreRunE: requireElevation,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var allOpts []any
-		var filterCount int
+		filters, err := filtersFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		if len(filters) == 0 {
+			return fmt.Errorf("at least one filter (--destination, --next-hop, --if-index, --if-alias, --if-alias-glob, --if-alias-prefix, --if-desc, --metric) must be provided for deletion")
+		}
+
+		// System routes (loopback, multicast, broadcast, link-local) are protected unless requested.
+		if includeSystem, _ := cmd.Flags().GetBool("include-system"); !includeSystem {
+			filters = append(filters, winroute.WithoutSystemRoutes())
+		}
+
+		allOpts := make([]any, 0, len(filters)+1)
+		for _, filter := range filters {
+			allOpts = append(allOpts, filter)
+		}
+		if stopOnError, _ := cmd.Flags().GetBool("stop-on-error"); stopOnError {
+			allOpts = append(allOpts, winroute.ErrorActionStop)
+		}
+		if one, _ := cmd.Flags().GetBool("one"); one {
+			allOpts = append(allOpts, winroute.DeleteOne)
+		}
+		if progress, _ := cmd.Flags().GetBool("progress"); progress {
+			allOpts = append(allOpts, winroute.WithProgress(func(done, total int, current *winroute.Route) {
+				fmt.Fprintf(stderr, "[%d/%d] %s\n", done, total, current)
+			}))
+		}
+
+		result, err := winroute.DeleteRoutesBatch(allOpts...)
+		if err != nil {
+			return err
+		}
+		if result.HasErrors() {
+			for _, routeErr := range result.Errors {
+				fmt.Fprintln(stderr, routeErr)
+			}
+			return fmt.Errorf("deleted %d routes with %d errors", result.Succeeded, result.Failed)
+		}
+
+		return nil // On success, print nothing.
+	},
+}
+
+// ---- countCmd ----
+var countCmd = &cobra.Command{
+	Use:   "count",
+	Short: "Count Windows routes matching filters",
+	Long:  `Prints the number of routes in the system's routing table that match the provided filters.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filters, err := filtersFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		count, err := winroute.CountRoutes(filters...)
+		if err != nil {
+			return fmt.Errorf("failed to count routes: %w", err)
+		}
+
+		fmt.Println(count)
+		return nil
+	},
+}
+
+// ---- checkCmd ----
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the routing table for common problems",
+	Long: `Scans the routing table and reports duplicate routes, routes on interfaces that are down,
+default routes that tie on metric, and next hops that are not reachable on-link.
+Exits with a non-zero status when problems are found.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		issues, err := winroute.ValidateRoutes()
+		if err != nil {
+			return fmt.Errorf("failed to check routes: %w", err)
+		}
+
+		if len(issues) == 0 {
+			fmt.Println("No issues found.")
+			return nil
+		}
+
+		for _, issue := range issues {
+			fmt.Printf("[%s] %s\n", issue.Kind, issue.Description)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			for _, route := range issue.Routes {
+				fmt.Fprintf(w, "    %s\t%s\t%d\t%d\t%s\n",
+					route.Destination,
+					route.NextHop,
+					route.Metric,
+					route.Interface.Index,
+					route.Interface.Alias,
+				)
+			}
+			w.Flush()
+		}
+		return fmt.Errorf("found %d issues", len(issues))
+	},
+}
 
-		// Build filter and option slices based on flags
-		if destStr, _ := cmd.Flags().GetString("destination"); destStr != "" {
-			prefix, err := netip.ParsePrefix(destStr)
+// ---- annotateCmd ----
+var annotateCmd = &cobra.Command{
+	Use:   "annotate",
+	Short: "Attach a note to a route",
+	Long: `Records a note explaining why a route exists. Notes are kept in a JSON file
+(by default in the user's config directory) and shown by "wroute get".
+An empty --note removes the route's note.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nextHopStr, _ := cmd.Flags().GetString("next-hop")
+		ifIndex, _ := cmd.Flags().GetUint32("if-index")
+		note, _ := cmd.Flags().GetString("note")
+
+		destination, err := parseDestination(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Without --next-hop the note applies to the on-link route, as with add.
+		nextHop := netip.IPv4Unspecified()
+		if destination.Addr().Is6() {
+			nextHop = netip.IPv6Unspecified()
+		}
+		if nextHopStr != "" {
+			nextHop, err = netip.ParseAddr(nextHopStr)
 			if err != nil {
-				return fmt.Errorf("invalid destination prefix '%s': %w", destStr, err)
+				return fmt.Errorf("invalid next-hop address '%s': %w", nextHopStr, err)
 			}
-			filter := winroute.WithDestinationPrefix(prefix)
-			allOpts = append(allOpts, filter)
-			filterCount++
 		}
-		if ifIndex, _ := cmd.Flags().GetUint32("if-index"); ifIndex > 0 {
-			filter := winroute.WithInterfaceIndex(ifIndex)
-			allOpts = append(allOpts, filter)
-			filterCount++
+
+		table, err := winroute.NewRouteTable()
+		if err != nil {
+			return err
 		}
-		if ifAlias, _ := cmd.Flags().GetString("if-alias"); ifAlias != "" {
-			filter := winroute.WithInterfaceAlias(ifAlias)
-			allOpts = append(allOpts, filter)
-			filterCount++
+		routes, err := table.GetRoutes(
+			winroute.WithDestinationPrefix(destination.Masked()),
+			winroute.WithInterfaceIndex(ifIndex),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to find route: %w", err)
 		}
-		if cmd.Flags().Changed("metric") {
-			metric, _ := cmd.Flags().GetUint32("metric")
-			filter := winroute.WithMetric(metric)
-			allOpts = append(allOpts, filter)
-			filterCount++
+		index := slices.IndexFunc(routes, func(r *winroute.Route) bool {
+			return r.NextHop.WithZone("") == nextHop.WithZone("")
+		})
+		if index < 0 {
+			return fmt.Errorf("route to %s via %s on interface %d: %w", destination.Masked(), nextHop, ifIndex, winroute.ErrNotFound)
 		}
 
-		if filterCount == 0 {
-			return fmt.Errorf("at least one filter (--destination, --if-index, --if-alias, --metric) must be provided for deletion")
+		setNotesFile(cmd, table)
+		return table.Annotate(routes[index], note)
+	},
+}
+
+// setNotesFile points the table at the notes file given by --notes-file, if any.
+func setNotesFile(cmd *cobra.Command, table *winroute.RouteTable) {
+	if path, _ := cmd.Flags().GetString("notes-file"); path != "" {
+		table.SetAnnotationPath(path)
+	}
+}
+
+// ---- doctorCmd ----
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check whether this environment can manage routes",
+	Long: `Reports whether the process is elevated, whether the Windows IP helper calls used by
+wroute work, and how many network adapters were found.
+Exits with a non-zero status when the self-test or listing the adapters fails.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		elevated, err := winroute.IsElevated()
+		switch {
+		case err != nil:
+			fmt.Printf("admin rights:  unknown (%v)\n", err)
+		case elevated:
+			fmt.Println("admin rights:  yes")
+		default:
+			fmt.Println("admin rights:  no (add, delete and apply need an elevated prompt)")
 		}
 
-		if stopOnError, _ := cmd.Flags().GetBool("stop-on-error"); stopOnError {
-			allOpts = append(allOpts, winroute.ErrorActionStop)
+		ifaces, ifaceErr := winroute.ListInterfaces()
+		if ifaceErr != nil {
+			fmt.Printf("adapters:      FAILED (%v)\n", ifaceErr)
+		} else {
+			fmt.Printf("adapters:      %d\n", len(ifaces))
+		}
+
+		if err := winroute.SelfTest(); err != nil {
+			fmt.Println("winipcfg:      FAILED")
+			return err
 		}
+		fmt.Println("winipcfg:      ok")
+		if ifaceErr != nil {
+			return fmt.Errorf("failed to list adapters: %w", ifaceErr)
+		}
+		return nil
+	},
+}
+
+// ---- dumpCmd ----
+var dumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Dump the routing table for bug reports",
+	Long: `Prints every route as wroute sees it, in JSON. With --raw, prints the
+MIB_IPFORWARD_ROW2 entries exactly as Windows returned them instead, with all
+fields and before any processing. Attach both outputs when reporting a route
+that wroute shows incorrectly.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if raw, _ := cmd.Flags().GetBool("raw"); raw {
+			return winroute.DumpRawTable(os.Stdout)
+		}
+		routes, err := winroute.GetRoutes()
+		if err != nil {
+			return err
+		}
+		return printRoutesJSON(os.Stdout, routes)
+	},
+}
 
-		partialErrs, err := winroute.DeleteRoutes(allOpts...)
+// ---- ifaceCmd ----
+var ifaceCmd = &cobra.Command{
+	Use:   "iface <index|alias>",
+	Short: "Show one interface with its metric, gateways and routes",
+	Long: `Prints everything about a single network interface: its addresses, status,
+interface metric and gateways, followed by every route that uses it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := winroute.GetInterfaceReport(args[0])
 		if err != nil {
 			return err
 		}
-		if len(partialErrs) > 0 {
+
+		iface := report.Interface
+		status := "down"
+		if iface.IsUp() {
+			status = "up"
+		}
+		metric := fmt.Sprint(report.Metric)
+		if report.AutomaticMetric {
+			metric += " (automatic)"
+		}
+
+		fmt.Printf("Interface %d (%s)\n", iface.Index, iface.Alias)
+		fmt.Printf("description:  %s\n", iface.Description)
+		fmt.Printf("type:         %s\n", iface.TypeName())
+		fmt.Printf("status:       %s\n", status)
+		fmt.Printf("speed:        %s\n", iface.SpeedName())
+		fmt.Printf("metric:       %s\n", metric)
+		for _, addr := range iface.Addresses {
+			fmt.Printf("address:      %s\n", addr)
+		}
+		for _, gateway := range report.Gateways {
+			fmt.Printf("gateway:      %s\n", gateway)
+		}
+
+		fmt.Printf("\n%d routes\n", len(report.Routes))
+		if len(report.Routes) == 0 {
+			return nil
+		}
+		return printRoutesTable(os.Stdout, report.Routes, nil)
+	},
+}
+
+// ---- applyCmd ----
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply a desired set of routes from a file",
+	Long: `Reads a JSON list of routes and carries out the changes "wroute plan" shows for
+it: routes that are not present are added and routes whose metric differs are
+updated, reporting each failure. With --prune, statically added routes on the
+interfaces named in the file that are not listed in it are deleted, so the file
+describes the desired state of those interfaces.
+
+Each entry has the form:
+  {"destination": "10.0.0.0/8", "next_hop": "192.168.1.1", "interface": "Ethernet", "metric": 10}
+"interface" is an interface index or alias; "next_hop" may be omitted for an
+on-link route; "metric" may be omitted to add no offset to the interface metric.`,
+	PreRunE: requireElevation,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("file")
+		entries, err := readRouteFile(path)
+		if err != nil {
+			return err
+		}
+
+		specs, err := routeSpecsFromEntries(entries)
+		if err != nil {
+			return err
+		}
+
+		// The same plan that "wroute plan" prints for this file.
+		toAdd, toDelete, toUpdate, err := winroute.PlanRoutes(specs)
+		if err != nil {
+			return fmt.Errorf("failed to plan routes: %w", err)
+		}
+
+		errorAction := winroute.ErrorActionContinue
+		if stopOnError, _ := cmd.Flags().GetBool("stop-on-error"); stopOnError {
+			errorAction = winroute.ErrorActionStop
+		}
+		addErrs, err := winroute.AddRoutes(toAdd, errorAction)
+		if err != nil {
+			return err
+		}
+		updateErrs, err := eachSpec(toUpdate, errorAction, func(spec winroute.RouteSpec) error {
+			_, partialErrs, err := winroute.SetMetricForRoutes(spec.Metric,
+				winroute.WithDestinationPrefix(spec.Destination),
+				winroute.WithNextHop(spec.NextHop),
+				winroute.WithInterfaceIndex(spec.InterfaceIndex))
+			if err != nil {
+				return err
+			}
+			return errors.Join(partialErrs...)
+		})
+		if err != nil {
+			return err
+		}
+		for _, partialErr := range append(addErrs, updateErrs...) {
+			fmt.Fprintln(stderr, partialErr)
+		}
+		failed := len(addErrs) + len(updateErrs)
+		fmt.Printf("added %d, updated %d, failed %d, unchanged %d\n",
+			len(toAdd)-len(addErrs), len(toUpdate)-len(updateErrs), failed, len(specs)-len(toAdd)-len(toUpdate))
+
+		if prune, _ := cmd.Flags().GetBool("prune"); prune {
+			partialErrs, err := eachSpec(toDelete, errorAction, func(spec winroute.RouteSpec) error {
+				return winroute.DeleteRoute(spec.Destination, spec.NextHop, spec.InterfaceIndex)
+			})
+			if err != nil {
+				return err
+			}
 			for _, partialErr := range partialErrs {
 				fmt.Fprintln(stderr, partialErr)
 			}
-			return fmt.Errorf("deleted routes with %d errors", len(partialErrs))
+			fmt.Printf("pruned %d\n", len(toDelete)-len(partialErrs))
+			failed += len(partialErrs)
 		}
 
-		return nil // On success, print nothing.
+		if failed > 0 {
+			return fmt.Errorf("applied routes with %d errors", failed)
+		}
+		return nil
+	},
+}
+
+// ---- planCmd ----
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show the changes needed to reach a desired set of routes",
+	Long: `Reads a route file in the format used by apply and prints the routes that would be
+added (+), updated to a new metric (~) and deleted (-) to make the routing table match it.
+Only statically added routes on the interfaces named in the file are candidates for
+deletion; the filter flags narrow the routes considered further. Nothing is changed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("file")
+		entries, err := readRouteFile(path)
+		if err != nil {
+			return err
+		}
+		specs, err := routeSpecsFromEntries(entries)
+		if err != nil {
+			return err
+		}
+		filters, err := filtersFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		toAdd, toDelete, toUpdate, err := winroute.PlanRoutes(specs, filters...)
+		if err != nil {
+			return fmt.Errorf("failed to plan routes: %w", err)
+		}
+		for _, spec := range toAdd {
+			fmt.Printf("+ %s\n", formatSpec(spec))
+		}
+		for _, spec := range toUpdate {
+			fmt.Printf("~ %s\n", formatSpec(spec))
+		}
+		for _, spec := range toDelete {
+			fmt.Printf("- %s\n", formatSpec(spec))
+		}
+		fmt.Printf("Plan: %d to add, %d to update, %d to delete.\n", len(toAdd), len(toUpdate), len(toDelete))
+		return nil
 	},
 }
 
+// formatSpec renders a route spec on one line for the plan output.
+func formatSpec(spec winroute.RouteSpec) string {
+	return fmt.Sprintf("%s via %s if %d metric %d", spec.Destination, spec.NextHop, spec.InterfaceIndex, spec.Metric)
+}
+
+// routeFileEntry is one route in the file read by apply.
+type routeFileEntry struct {
+	Destination string  `json:"destination"`
+	NextHop     string  `json:"next_hop"`
+	Interface   string  `json:"interface"`
+	Metric      *uint32 `json:"metric,omitempty"`
+}
+
+// readRouteFile decodes the route list at path; "-" reads from standard input.
+func readRouteFile(path string) ([]routeFileEntry, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open route file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var entries []routeFileEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to parse route file '%s': %w", path, err)
+	}
+	return entries, nil
+}
+
+// routeSpecsFromEntries parses the entries and resolves their interfaces in one batch.
+func routeSpecsFromEntries(entries []routeFileEntry) ([]winroute.RouteSpec, error) {
+	identifiers := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		identifiers = append(identifiers, entry.Interface)
+	}
+	resolved, errs := winroute.ResolveInterfaces(identifiers)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	specs := make([]winroute.RouteSpec, 0, len(entries))
+	for i, entry := range entries {
+		destination, err := netip.ParsePrefix(entry.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: invalid destination prefix '%s': %w", i, entry.Destination, err)
+		}
+		// Like add without --next-hop, an empty next_hop makes the route on-link.
+		nextHop := netip.IPv4Unspecified()
+		if !destination.Addr().Is4() {
+			nextHop = netip.IPv6Unspecified()
+		}
+		if entry.NextHop != "" {
+			nextHop, err = netip.ParseAddr(entry.NextHop)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: invalid next-hop address '%s': %w", i, entry.NextHop, err)
+			}
+		}
+		spec := winroute.RouteSpec{
+			Destination:    destination,
+			NextHop:        nextHop,
+			InterfaceIndex: resolved[entry.Interface].Index,
+		}
+		if entry.Metric != nil {
+			spec.Metric = *entry.Metric
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// eachSpec calls op for each spec and collects the failures, each prefixed with
+// the spec. With ErrorActionStop the first failure is returned as err instead.
+func eachSpec(specs []winroute.RouteSpec, errorAction winroute.ErrorAction, op func(winroute.RouteSpec) error) (partialErrs []error, err error) {
+	for _, spec := range specs {
+		if err := op(spec); err != nil {
+			err = fmt.Errorf("%s: %w", formatSpec(spec), err)
+			if errorAction == winroute.ErrorActionStop {
+				return nil, err
+			}
+			partialErrs = append(partialErrs, err)
+		}
+	}
+	return partialErrs, nil
+}
+
+// ---- filter flags ----
+
+// addFilterFlags registers the route filter flags shared by get, count and delete.
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("destination", "d", "", "Filter by destination prefix (e.g., 192.168.1.0/24)")
+	cmd.Flags().Uint32P("if-index", "i", 0, "Filter by interface index")
+	cmd.Flags().StringP("if-alias", "a", "", "Filter by interface alias (case-insensitive)")
+	cmd.Flags().String("if-alias-glob", "", "Filter by interface alias glob pattern (case-insensitive, e.g., \"vEthernet *\")")
+	cmd.Flags().String("if-alias-prefix", "", "Filter by interface alias prefix (case-insensitive, e.g., \"vEthernet\")")
+	cmd.Flags().Uint32P("metric", "m", 0, "Filter by route metric")
+	cmd.Flags().String("if-desc", "", "Filter by interface description substring (case-insensitive)")
+	cmd.Flags().Duration("min-age", 0, "Filter to routes that have existed for at least this long (e.g., 24h)")
+	cmd.Flags().Duration("max-age", 0, "Filter to routes added within this duration (e.g., 10m)")
+	cmd.Flags().String("mask", "", "Dotted netmask for --destination given as a plain address (e.g., -d 10.0.0.0 --mask 255.0.0.0)")
+}
+
+// filtersFromFlags builds the library filters selected by the flags from addFilterFlags.
+func filtersFromFlags(cmd *cobra.Command) ([]winroute.FilterOption, error) {
+	var filters []winroute.FilterOption
+
+	// Destination Prefix Filter
+	destStr, _ := cmd.Flags().GetString("destination")
+	if maskStr, _ := cmd.Flags().GetString("mask"); maskStr != "" && destStr == "" {
+		return nil, errors.New("--mask can only be used together with --destination")
+	}
+	if destStr != "" {
+		prefix, err := parseDestination(cmd)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, winroute.WithDestinationPrefix(prefix))
+	}
+
+	// Next Hop Filter (only registered by commands that accept it, e.g. delete)
+	if nextHopStr, _ := cmd.Flags().GetString("next-hop"); nextHopStr != "" {
+		nextHop, err := netip.ParseAddr(nextHopStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid next-hop address '%s': %w", nextHopStr, err)
+		}
+		filters = append(filters, winroute.WithNextHop(nextHop))
+	}
+
+	// Interface Index Filter
+	if ifIndex, _ := cmd.Flags().GetUint32("if-index"); ifIndex > 0 {
+		filters = append(filters, winroute.WithInterfaceIndex(ifIndex))
+	}
+
+	// Interface Alias Filter
+	if ifAlias, _ := cmd.Flags().GetString("if-alias"); ifAlias != "" {
+		filters = append(filters, winroute.WithInterfaceAlias(ifAlias))
+	}
+
+	// Interface Alias Pattern Filter
+	if pattern, _ := cmd.Flags().GetString("if-alias-glob"); pattern != "" {
+		filters = append(filters, winroute.WithInterfaceAliasPattern(pattern))
+	}
+
+	// Interface Alias Prefix Filter
+	if prefix, _ := cmd.Flags().GetString("if-alias-prefix"); prefix != "" {
+		filters = append(filters, winroute.WithInterfaceAliasPrefix(prefix))
+	}
+
+	// Interface Description Filter
+	if ifDesc, _ := cmd.Flags().GetString("if-desc"); ifDesc != "" {
+		filters = append(filters, winroute.WithInterfaceDescription(ifDesc))
+	}
+
+	// Metric Filter
+	if cmd.Flags().Changed("metric") {
+		metric, _ := cmd.Flags().GetUint32("metric")
+		filters = append(filters, winroute.WithMetric(metric))
+	}
+
+	// Route Age Filters
+	if cmd.Flags().Changed("min-age") {
+		age, _ := cmd.Flags().GetDuration("min-age")
+		filters = append(filters, winroute.WithMinAge(age))
+	}
+	if cmd.Flags().Changed("max-age") {
+		age, _ := cmd.Flags().GetDuration("max-age")
+		filters = append(filters, winroute.WithMaxAge(age))
+	}
+
+	// Gateway Routes Filter (only registered on some commands)
+	if gatewaysOnly, _ := cmd.Flags().GetBool("gateways-only"); gatewaysOnly {
+		filters = append(filters, winroute.WithGatewayRoutes())
+	}
+
+	// Prefix Length Filter (only registered on some commands)
+	if cmd.Flags().Changed("prefix-len") {
+		bits, _ := cmd.Flags().GetInt("prefix-len")
+		if bits < 0 || bits > 128 {
+			return nil, fmt.Errorf("invalid prefix length %d: must be between 0 and 128", bits)
+		}
+		filters = append(filters, winroute.WithPrefixLength(bits))
+	}
+
+	return filters, nil
+}
+
+// parseDestination parses the --destination flag, either as a CIDR prefix or,
+// when --mask is given, as a network address combined with a dotted netmask.
+func parseDestination(cmd *cobra.Command) (netip.Prefix, error) {
+	destStr, _ := cmd.Flags().GetString("destination")
+	maskStr, _ := cmd.Flags().GetString("mask")
+	if maskStr == "" {
+		prefix, err := netip.ParsePrefix(destStr)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid destination prefix '%s': %w", destStr, err)
+		}
+		return prefix, nil
+	}
+
+	network, err := netip.ParseAddr(destStr)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid destination address '%s': %w", destStr, err)
+	}
+	mask, err := netip.ParseAddr(maskStr)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid netmask '%s': %w", maskStr, err)
+	}
+	prefix, err := winroute.PrefixFromNetmask(network, mask)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid destination '%s' mask '%s': %w", destStr, maskStr, err)
+	}
+	return prefix, nil
+}
+
 // ---- init ----
 func init() {
 	// Add subcommands to root
@@ -227,34 +1023,75 @@ func init() {
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(deleteRouteCmd)
 	rootCmd.AddCommand(deleteCmd)
+	rootCmd.AddCommand(countCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(ifaceCmd)
+	rootCmd.AddCommand(dumpCmd)
+	rootCmd.AddCommand(annotateCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(planCmd)
 
 	// Flags for 'get' command
-	getCmd.Flags().StringP("destination", "d", "", "Filter by destination prefix (e.g., 192.168.1.0/24)")
-	getCmd.Flags().Uint32P("if-index", "i", 0, "Filter by interface index")
-	getCmd.Flags().StringP("if-alias", "a", "", "Filter by interface alias (case-insensitive)")
-	getCmd.Flags().Uint32P("metric", "m", 0, "Filter by route metric")
+	addFilterFlags(getCmd)
+	getCmd.Flags().StringP("output", "o", outputTable, "Output format: table, json, csv or route-print")
+	getCmd.Flags().String("group-by", "", "Group the table output; the only supported value is 'interface'")
+	getCmd.Flags().String("notes-file", "", "Route notes file (default: winroute\\notes.json in the user config directory)")
+	getCmd.Flags().Int("limit", 0, "Show at most this many routes, in routing table order")
+	getCmd.Flags().Bool("gateways-only", false, "Show only routes through a gateway (skip on-link and local routes)")
+	getCmd.Flags().Int("prefix-len", 0, "Filter by destination prefix length (e.g., 32 for IPv4 host routes, 0 for default routes)")
 
 	// Flags for 'add' command
 	addCmd.Flags().StringP("destination", "d", "", "Destination prefix for the new route (e.g., 10.0.0.0/8)")
-	addCmd.Flags().StringP("next-hop", "n", "", "Next hop address for the new route (e.g., 192.168.1.1)")
+	addCmd.Flags().StringP("next-hop", "n", "", "Next hop address for the new route (e.g., 192.168.1.1); omit for an on-link route out the interface")
 	addCmd.Flags().Uint32P("if-index", "i", 0, "Interface index for the new route")
-	addCmd.Flags().Uint32P("metric", "m", 0, "Metric for the new route (lower is more preferred)")
+	addCmd.Flags().Uint32P("metric", "m", 0, "Metric offset for the new route (lower is more preferred); 0 adds nothing to the interface metric")
+	addCmd.Flags().String("mask", "", "Dotted netmask for --destination given as a plain address (e.g., -d 10.0.0.0 --mask 255.0.0.0)")
 	addCmd.MarkFlagRequired("destination")
-	addCmd.MarkFlagRequired("next-hop")
 	addCmd.MarkFlagRequired("if-index")
 
 	// Flags for 'delete-one' command
 	deleteRouteCmd.Flags().StringP("destination", "d", "", "Destination prefix of the route to delete (e.g., 10.0.0.0/8)")
 	deleteRouteCmd.Flags().StringP("next-hop", "n", "", "Next hop address of the route to delete (e.g., 192.168.1.1)")
 	deleteRouteCmd.Flags().Uint32P("if-index", "i", 0, "Interface index of the route to delete")
+	deleteRouteCmd.Flags().String("mask", "", "Dotted netmask for --destination given as a plain address (e.g., -d 10.0.0.0 --mask 255.0.0.0)")
 	deleteRouteCmd.MarkFlagRequired("destination")
 	deleteRouteCmd.MarkFlagRequired("next-hop")
 	deleteRouteCmd.MarkFlagRequired("if-index")
 
+	// Flags for 'annotate' command
+	annotateCmd.Flags().StringP("destination", "d", "", "Destination prefix of the route to annotate (e.g., 10.0.0.0/8)")
+	annotateCmd.Flags().StringP("next-hop", "n", "", "Next hop address of the route; omit for an on-link route")
+	annotateCmd.Flags().Uint32P("if-index", "i", 0, "Interface index of the route")
+	annotateCmd.Flags().String("mask", "", "Dotted netmask for --destination given as a plain address (e.g., -d 10.0.0.0 --mask 255.0.0.0)")
+	annotateCmd.Flags().String("note", "", "Note to attach to the route; empty removes the existing note")
+	annotateCmd.Flags().String("notes-file", "", "Route notes file (default: winroute\\notes.json in the user config directory)")
+	annotateCmd.MarkFlagRequired("destination")
+	annotateCmd.MarkFlagRequired("if-index")
+	annotateCmd.MarkFlagRequired("note")
+
+	// Flags for 'count' command
+	addFilterFlags(countCmd)
+
 	// Flags for 'delete' command
-	deleteCmd.Flags().StringP("destination", "d", "", "Filter by destination prefix (e.g., 192.168.1.0/24)")
-	deleteCmd.Flags().Uint32P("if-index", "i", 0, "Filter by interface index")
-	deleteCmd.Flags().StringP("if-alias", "a", "", "Filter by interface alias (case-insensitive)")
-	deleteCmd.Flags().Uint32P("metric", "m", 0, "Filter by route metric")
+	addFilterFlags(deleteCmd)
+	deleteCmd.Flags().StringP("next-hop", "n", "", "Filter by next hop address (e.g., 192.168.1.1; 0.0.0.0 for on-link routes)")
 	deleteCmd.Flags().Bool("stop-on-error", false, "Stop the operation on the first error")
+	deleteCmd.Flags().Bool("one", false, "Delete only the most specific matching route (longest prefix, then lowest metric)")
+	deleteCmd.Flags().Bool("progress", false, "Report each processed route on stderr")
+	deleteCmd.Flags().Bool("include-system", false, "Also delete system routes (loopback, multicast, broadcast, link-local)")
+
+	// Flags for 'dump' command
+	dumpCmd.Flags().Bool("raw", false, "Print the unprocessed MIB_IPFORWARD_ROW2 entries returned by Windows")
+
+	// Flags for 'apply' command
+	applyCmd.Flags().StringP("file", "f", "", "JSON file listing the desired routes ('-' for standard input)")
+	applyCmd.Flags().Bool("prune", false, "Delete static routes on the listed interfaces that are not in the file")
+	applyCmd.Flags().Bool("stop-on-error", false, "Stop adding or pruning routes on the first error")
+	applyCmd.MarkFlagRequired("file")
+
+	// Flags for 'plan' command
+	addFilterFlags(planCmd)
+	planCmd.Flags().StringP("file", "f", "", "JSON file listing the desired routes ('-' for standard input)")
+	planCmd.MarkFlagRequired("file")
 }
@@ -0,0 +1,100 @@
+package winroute
+
+import (
+	"net/netip"
+
+	"github.com/bnkrr/winroute/internal/routediff"
+	"github.com/bnkrr/winroute/internal/routehash"
+	"github.com/bnkrr/winroute/internal/routeset"
+)
+
+// routeIdentity 是用于比较两个路由快照的路由身份：目标、下一跳和接口索引。
+type routeIdentity struct {
+	destination netip.Prefix
+	nextHop     netip.Addr
+	ifaceIndex  uint32
+}
+
+// newRouteIdentity 返回规范化后的路由身份，使从系统读回的路由（identityOf）与
+// 待添加的 RouteSpec（specIdentity）可以直接比较：目标清除主机位，零值下一跳视为链路直连，
+// 下一跳去掉 zone（接口已由 ifaceIndex 确定，例如 fe80::1%5）并把 IPv4 映射地址转换为 IPv4。
+func newRouteIdentity(destination netip.Prefix, nextHop netip.Addr, ifaceIndex uint32) routeIdentity {
+	destination, _ = normalizeDestination(destination)
+	return routeIdentity{
+		destination: destination,
+		nextHop:     onLinkNextHop(destination, nextHop).WithZone("").Unmap(),
+		ifaceIndex:  ifaceIndex,
+	}
+}
+
+func identityOf(r *Route) routeIdentity {
+	return newRouteIdentity(r.Destination, r.NextHop, r.Interface.Index)
+}
+
+// specIdentity 返回 spec 创建的路由的身份，与从系统读回的路由的 identityOf 一致。
+func specIdentity(spec RouteSpec) routeIdentity {
+	return newRouteIdentity(spec.Destination, spec.NextHop, spec.InterfaceIndex)
+}
+
+// DiffRoutes 比较两个路由快照（例如两次 GetRoutes 的结果）。
+//
+// 路由按 (Destination, NextHop, Interface.Index) 识别：
+//   - added: 只出现在 newRoutes 中的路由。
+//   - removed: 只出现在 oldRoutes 中的路由。
+//   - changed: 两边都存在，但 Metric、Protocol 或 Origin 发生变化的路由（取 newRoutes 中的版本）。
+//
+// 有效期/首选期会随时间自然递减，因此不参与比较。
+func DiffRoutes(oldRoutes, newRoutes []*Route) (added, removed, changed []*Route) {
+	return routediff.Diff(oldRoutes, newRoutes, identityOf, func(oldRoute, newRoute *Route) bool {
+		return oldRoute.Metric != newRoute.Metric ||
+			oldRoute.Protocol != newRoute.Protocol ||
+			oldRoute.Origin != newRoute.Origin
+	})
+}
+
+// RoutesHash 返回匹配 filters 的路由的 64 位哈希，用于低成本地检测路由表变化：
+// 轮询时只需比较哈希，哈希改变后再获取完整路由表并用 DiffRoutes 比较。
+//
+// 哈希覆盖 Route.Equal 比较的字段（目标、下一跳、接口索引和 metric），与路由顺序无关，
+// 并且在不同进程和不同运行之间保持稳定。
+func RoutesHash(filters ...FilterOption) (uint64, error) {
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return 0, err
+	}
+	var routes []routehash.Route
+	err = scanRoutes(cache, filters, func(r *Route) bool {
+		routes = append(routes, routehash.Route{
+			Destination:    r.Destination,
+			NextHop:        r.NextHop,
+			InterfaceIndex: r.Interface.Index,
+			Metric:         r.Metric,
+		})
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return routehash.Sum(routes), nil
+}
+
+// ---- 路由集合运算 ----
+//
+// 以下函数把路由切片视为集合，两条路由相同当且仅当 Route.Equal 返回 true。
+// 结果中不会出现相等的重复路由（保留首次出现的那条），切片中不能包含 nil。
+
+// RoutesUnion 返回 a 中的路由，以及 b 中不等于 a 中任何路由的路由，按先 a 后 b 的顺序排列。
+func RoutesUnion(a, b []*Route) []*Route {
+	return routeset.Union(a, b, equalKeyOf)
+}
+
+// RoutesIntersection 返回 a 中等于 b 中某条路由的路由，按 a 的顺序排列。
+func RoutesIntersection(a, b []*Route) []*Route {
+	return routeset.Intersection(a, b, equalKeyOf)
+}
+
+// RoutesDifference 返回 a 中不等于 b 中任何路由的路由，按 a 的顺序排列。
+// 例如 RoutesDifference(desired, current) 是需要添加的路由，RoutesDifference(current, desired) 是需要删除的路由。
+func RoutesDifference(a, b []*Route) []*Route {
+	return routeset.Difference(a, b, equalKeyOf)
+}
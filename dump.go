@@ -0,0 +1,67 @@
+package winroute
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// DumpRawTable 把系统返回的原始路由表（GetIPForwardTable2 返回的 MIB_IPFORWARD_ROW2）逐行写入 w，
+// 包含结构体的全部字段，数值保持原样，不经过接口解析、过滤或 zone 等处理，
+// 接口不存在的路由也会输出。用于排查 GetRoutes 的结果与系统不一致的问题，例如附在问题报告中。
+//
+// 输出格式面向人阅读，不保证在版本之间保持稳定。
+func DumpRawTable(w io.Writer) error {
+	rows, err := provider.routeTable(winapi.AF_UNSPEC)
+	if err != nil {
+		return fmt.Errorf("failed to get route table: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "%d rows\n", len(rows)); err != nil {
+		return err
+	}
+	for i := range rows {
+		if err := dumpRawRow(w, i, &rows[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpRawRow 写出第 i 行的全部字段，每个字段一行。
+func dumpRawRow(w io.Writer, i int, row *winapi.MibIPforwardRow2) error {
+	_, err := fmt.Fprintf(w, "\n[%d]\n"+
+		"  InterfaceLUID         %d\n"+
+		"  InterfaceIndex        %d\n"+
+		"  DestinationPrefix     family=%d address=%s length=%d\n"+
+		"  NextHop               family=%d address=%s\n"+
+		"  SitePrefixLength      %d\n"+
+		"  ValidLifetime         %d\n"+
+		"  PreferredLifetime     %d\n"+
+		"  Metric                %d\n"+
+		"  Protocol              %d\n"+
+		"  Loopback              %t\n"+
+		"  AutoconfigureAddress  %t\n"+
+		"  Publish               %t\n"+
+		"  Immortal              %t\n"+
+		"  Age                   %d\n"+
+		"  Origin                %d\n",
+		i,
+		row.InterfaceLUID,
+		row.InterfaceIndex,
+		row.DestinationPrefix.RawPrefix.Family, row.DestinationPrefix.RawPrefix.Addr(), row.DestinationPrefix.PrefixLength,
+		row.NextHop.Family, row.NextHop.Addr(),
+		row.SitePrefixLength,
+		row.ValidLifetime,
+		row.PreferredLifetime,
+		row.Metric,
+		row.Protocol,
+		row.Loopback,
+		row.AutoconfigureAddress,
+		row.Publish,
+		row.Immortal,
+		row.Age,
+		row.Origin,
+	)
+	return err
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package winroute
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// IsElevated 判断当前进程是否拥有管理员权限，即进程令牌中的 Administrators 组处于启用状态。
+// 在启用了 UAC 的系统上，未以管理员身份运行的进程即使用户属于 Administrators 组也返回 false。
+// 增删路由需要管理员权限。
+func IsElevated() (bool, error) {
+	adminSID, err := windows.CreateWellKnownSid(windows.WinBuiltinAdministratorsSid)
+	if err != nil {
+		return false, fmt.Errorf("failed to create administrators SID: %w", err)
+	}
+	// Token(0) 表示使用当前线程的模拟令牌，没有时使用进程令牌，与 CheckTokenMembership 一致。
+	member, err := windows.Token(0).IsMember(adminSID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check token membership: %w", err)
+	}
+	return member, nil
+}
@@ -1,54 +1,53 @@
-//go:build windows
-
 package winroute
 
 import (
+	"errors"
 	"fmt"
+	"path"
 	"strconv"
-	"strings"
 
-	"golang.org/x/sys/windows"
-	"golang.zx2c4.com/wireguard/windows/tunnel/winipcfg"
+	"github.com/bnkrr/winroute/internal/aliasfold"
+	"github.com/bnkrr/winroute/internal/winapi"
 )
 
 // ---- 辅助工具：接口缓存和查询 ----
 
 // interfaceCache 用于在单次操作中缓存接口信息，避免重复的API调用。
 type interfaceCache struct {
-	byLUID     map[winipcfg.LUID]*Interface
+	all        []*Interface // 按系统返回顺序排列的全部接口
+	byLUID     map[winapi.LUID]*Interface
 	byIndex    map[uint32]*Interface
-	byAlias    map[string]*Interface
+	byAlias    map[string]*Interface // 以 aliasfold.Key 规范化后的别名为键
 	aliasCount map[string]int
+	family     winapi.AddressFamily // 构建缓存时请求的地址族，见 newInterfaceCacheFamily
+	rebuilt    bool                 // 由 currentLUID 因索引过期而重建，见 currentLUID
 }
 
 // newInterfaceCache 通过查询系统API来构建接口信息的完整缓存。
 func newInterfaceCache() (*interfaceCache, error) {
-	// 使用 winipcfg 获取大部分接口信息
-	adapters, err := winipcfg.GetAdaptersAddresses(windows.AF_UNSPEC, windows.GAA_FLAG_INCLUDE_PREFIX)
+	return newInterfaceCacheFamily(winapi.AF_UNSPEC)
+}
+
+// newInterfaceCacheFamily 与 newInterfaceCache 相同，但 family 不为 AF_UNSPEC 时只向系统请求
+// 该地址族的适配器信息：缓存中只有启用了该地址族的接口，且只包含该地址族的地址、网关和 metric。
+func newInterfaceCacheFamily(family winapi.AddressFamily) (*interfaceCache, error) {
+	ifaces, err := provider.interfaces(family)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get adapters addresses: %w", err)
+		return nil, err
 	}
 
 	cache := &interfaceCache{
-		byLUID:     make(map[winipcfg.LUID]*Interface, len(adapters)),
-		byIndex:    make(map[uint32]*Interface, len(adapters)),
-		byAlias:    make(map[string]*Interface, len(adapters)),
-		aliasCount: make(map[string]int, len(adapters)),
-	}
-
-	for _, adapter := range adapters {
-		// adapter.FriendlyName() 通常就是我们需要的接口 "别名" (Alias)，
-		// 例如 "以太网" 或 "Wi-Fi"。直接使用它可以简化代码。
-		iface := &Interface{
-			Index:       adapter.IfIndex,
-			LUID:        adapter.LUID,
-			Alias:       adapter.FriendlyName(),
-			Description: adapter.Description(),
-		}
-
+		all:        ifaces,
+		byLUID:     make(map[winapi.LUID]*Interface, len(ifaces)),
+		byIndex:    make(map[uint32]*Interface, len(ifaces)),
+		byAlias:    make(map[string]*Interface, len(ifaces)),
+		aliasCount: make(map[string]int, len(ifaces)),
+		family:     family,
+	}
+	for _, iface := range ifaces {
 		cache.byLUID[iface.LUID] = iface
 		cache.byIndex[iface.Index] = iface
-		key := strings.ToLower(iface.Alias)
+		key := aliasfold.Key(iface.Alias)
 		cache.aliasCount[key]++
 		if _, exists := cache.byAlias[key]; !exists {
 			cache.byAlias[key] = iface
@@ -57,6 +56,61 @@ func newInterfaceCache() (*interfaceCache, error) {
 	return cache, nil
 }
 
+// buildInterfaceCache 构建接口缓存，并统一包装错误信息。
+func buildInterfaceCache() (*interfaceCache, error) {
+	return buildInterfaceCacheFamily(winapi.AF_UNSPEC)
+}
+
+// buildInterfaceCacheFamily 与 buildInterfaceCache 相同，但只构建 family 地址族的接口缓存。
+func buildInterfaceCacheFamily(family winapi.AddressFamily) (*interfaceCache, error) {
+	cache, err := newInterfaceCacheFamily(family)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build interface cache: %w", err)
+	}
+	return cache, nil
+}
+
+// luidFromIndex 将接口索引转换为 LUID。
+// cache 不为 nil 时直接从缓存中查找，否则调用系统 API。
+func luidFromIndex(index uint32, cache *interfaceCache) (winapi.LUID, error) {
+	if cache != nil {
+		if iface, ok := cache.byIndex[index]; ok {
+			return iface.LUID, nil
+		}
+		return 0, fmt.Errorf("interface with index %d not found: %w", index, ErrNotFound)
+	}
+	luid, err := provider.luidFromIndex(index)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert interface index to LUID: %w", err)
+	}
+	return luid, nil
+}
+
+// currentLUID 通过系统 API 重新将接口索引转换为 LUID，而不信任 cache 中的对应关系：
+// 适配器断开重连后索引可能改变，原来的索引也可能被另一个适配器复用，而 LUID 保持不变，
+// 长期持有的缓存（例如 RouteTable 的缓存）因此可能把索引对应到错误的适配器。
+// cache 中该索引对应的 LUID 与系统一致时原样返回 cache，否则返回按当前系统状态重新构建的缓存；
+// 由 currentLUID 重建的缓存不会再次重建，批量操作因此最多重建一次缓存。
+// cache 为 nil 时返回的缓存也为 nil；出错时返回原来的 cache。接口不存在时返回 ErrNotFound。
+func currentLUID(index uint32, cache *interfaceCache) (winapi.LUID, *interfaceCache, error) {
+	luid, err := IndexToLUID(index)
+	if err != nil {
+		return 0, cache, err
+	}
+	if cache == nil {
+		return luid, nil, nil
+	}
+	if iface, ok := cache.byIndex[index]; (ok && iface.LUID == luid) || cache.rebuilt {
+		return luid, cache, nil
+	}
+	rebuilt, err := buildInterfaceCacheFamily(cache.family)
+	if err != nil {
+		return 0, cache, err
+	}
+	rebuilt.rebuilt = true
+	return luid, rebuilt, nil
+}
+
 // findInterface 根据标识符（可以是Index或Alias）在缓存中查找接口。
 func (c *interfaceCache) findInterface(identifier string) (*Interface, error) {
 	// 尝试按 Index 解析
@@ -67,9 +121,140 @@ func (c *interfaceCache) findInterface(identifier string) (*Interface, error) {
 	}
 
 	// 尝试按 Alias 查找
-	if iface, ok := c.byAlias[strings.ToLower(identifier)]; ok {
+	if iface, ok := c.byAlias[aliasfold.Key(identifier)]; ok {
 		return iface, nil
 	}
 
 	return nil, fmt.Errorf("interface '%s' not found: %w", identifier, ErrNotFound)
 }
+
+// resolveInterface 与 findInterface 相同，但当标识符作为别名匹配到多个接口时返回 ErrAmbiguousMatch。
+func (c *interfaceCache) resolveInterface(identifier string) (*Interface, error) {
+	if index, err := strconv.ParseUint(identifier, 10, 32); err == nil {
+		if iface, ok := c.byIndex[uint32(index)]; ok {
+			return iface, nil
+		}
+	}
+	if err := validateUniqueAlias(c, identifier); err != nil {
+		return nil, err
+	}
+	return c.findInterface(identifier)
+}
+
+// interfacesMatching 返回别名与 glob 模式 pattern 匹配（不区分大小写，语法同 path.Match）的所有接口，
+// 顺序与接口缓存一致。模式无效时返回错误；没有接口匹配时返回 ErrNotFound。
+func (c *interfaceCache) interfacesMatching(pattern string) ([]*Interface, error) {
+	pattern = aliasfold.Key(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid interface alias pattern %q: %w", pattern, err)
+	}
+	var matched []*Interface
+	for _, iface := range c.all {
+		if ok, _ := path.Match(pattern, aliasfold.Key(iface.Alias)); ok {
+			matched = append(matched, iface)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no interface alias matches pattern %q: %w", pattern, ErrNotFound)
+	}
+	return matched, nil
+}
+
+// ---- 公开的接口查询 ----
+
+// FindInterfaceByLUID 根据 LUID 查找接口。接口不存在时返回 ErrNotFound。
+func FindInterfaceByLUID(luid winapi.LUID) (*Interface, error) {
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return nil, err
+	}
+	if iface, ok := cache.byLUID[luid]; ok {
+		return iface, nil
+	}
+	return nil, fmt.Errorf("interface with LUID %d not found: %w", luid, ErrNotFound)
+}
+
+// FindInterfaceByIndex 根据接口索引查找接口。接口不存在时返回 ErrNotFound。
+func FindInterfaceByIndex(index uint32) (*Interface, error) {
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return nil, err
+	}
+	if iface, ok := cache.byIndex[index]; ok {
+		return iface, nil
+	}
+	return nil, fmt.Errorf("interface with index %d not found: %w", index, ErrNotFound)
+}
+
+// IndexToLUID 将接口索引转换为 LUID，调用方无需直接使用 winipcfg。接口不存在时返回 ErrNotFound。
+func IndexToLUID(index uint32) (winapi.LUID, error) {
+	luid, err := provider.luidFromIndex(index)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert interface index %d to LUID: %w", index, mapInterfaceNotFound(err))
+	}
+	return luid, nil
+}
+
+// LUIDToIndex 将接口 LUID 转换为接口索引。接口不存在时返回 ErrNotFound。
+func LUIDToIndex(luid winapi.LUID) (uint32, error) {
+	index, err := provider.indexFromLUID(luid)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert interface LUID %d to index: %w", luid, mapInterfaceNotFound(err))
+	}
+	return index, nil
+}
+
+// mapInterfaceNotFound 将系统 API 表示接口不存在的错误映射为 ErrNotFound，其他错误原样返回。
+func mapInterfaceNotFound(err error) error {
+	if errors.Is(err, winapi.ERROR_FILE_NOT_FOUND) || errors.Is(err, winapi.ERROR_NOT_FOUND) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
+
+// ListInterfaces 返回系统中的所有网络接口。
+func ListInterfaces() ([]*Interface, error) {
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return nil, err
+	}
+	return cache.all, nil
+}
+
+// ListActiveInterfaces 返回所有处于运行状态（OperStatus 为 IfOperStatusUp）的网络接口。
+func ListActiveInterfaces() ([]*Interface, error) {
+	ifaces, err := ListInterfaces()
+	if err != nil {
+		return nil, err
+	}
+	active := make([]*Interface, 0, len(ifaces))
+	for _, iface := range ifaces {
+		if iface.IsUp() {
+			active = append(active, iface)
+		}
+	}
+	return active, nil
+}
+
+// ResolveInterfaces 使用同一个接口缓存解析一批接口标识符（索引或别名）。
+// 返回的 map 以原始标识符为键，只包含解析成功的接口；
+// 每个解析失败的标识符对应一个错误（ErrNotFound 或 ErrAmbiguousMatch）。
+// 构建接口缓存失败时，返回的错误列表只包含这一个错误。
+func ResolveInterfaces(identifiers []string) (map[string]*Interface, []error) {
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	resolved := make(map[string]*Interface, len(identifiers))
+	var errs []error
+	for _, identifier := range identifiers {
+		iface, err := cache.resolveInterface(identifier)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		resolved[identifier] = iface
+	}
+	return resolved, errs
+}
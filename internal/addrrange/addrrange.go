@@ -0,0 +1,49 @@
+// Package addrrange matches prefixes against inclusive address ranges.
+package addrrange
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// Range is an inclusive range of addresses of a single family.
+type Range struct {
+	Start, End netip.Addr
+}
+
+// New validates start and end and returns the range between them. IPv4-mapped
+// IPv6 addresses are treated as IPv4.
+func New(start, end netip.Addr) (Range, error) {
+	start, end = start.Unmap(), end.Unmap()
+	if !start.IsValid() || !end.IsValid() {
+		return Range{}, fmt.Errorf("invalid address range %s-%s", start, end)
+	}
+	if start.Is4() != end.Is4() {
+		return Range{}, fmt.Errorf("address range %s-%s mixes address families", start, end)
+	}
+	if end.Less(start) {
+		return Range{}, fmt.Errorf("address range %s-%s ends before it starts", start, end)
+	}
+	return Range{Start: start, End: end}, nil
+}
+
+// ContainsPrefix reports whether every address of prefix lies within r.
+// Prefixes of the other address family never match.
+func (r Range) ContainsPrefix(prefix netip.Prefix) bool {
+	if !prefix.IsValid() || prefix.Addr().Is4() != r.Start.Is4() {
+		return false
+	}
+	first := prefix.Masked().Addr().WithZone("")
+	return r.Start.Compare(first) <= 0 && LastAddr(prefix).Compare(r.End) <= 0
+}
+
+// LastAddr returns the highest address in prefix.
+func LastAddr(prefix netip.Prefix) netip.Addr {
+	addr := prefix.Masked().Addr().WithZone("")
+	b := addr.AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	last, _ := netip.AddrFromSlice(b)
+	return last
+}
@@ -0,0 +1,81 @@
+package addrrange
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestContainsPrefix(t *testing.T) {
+	tests := []struct {
+		start, end string
+		prefix     string
+		want       bool
+	}{
+		{start: "10.0.0.0", end: "10.0.255.255", prefix: "10.0.0.0/16", want: true},
+		{start: "10.0.0.0", end: "10.0.255.255", prefix: "10.0.4.0/24", want: true},
+		{start: "10.0.0.0", end: "10.0.255.255", prefix: "10.0.255.255/32", want: true},
+		{start: "10.0.0.0", end: "10.0.255.255", prefix: "10.0.0.0/8", want: false},
+		{start: "10.0.0.0", end: "10.0.255.255", prefix: "10.1.0.0/24", want: false},
+		{start: "10.0.0.1", end: "10.0.0.255", prefix: "10.0.0.0/24", want: false},
+		{start: "10.0.0.0", end: "10.0.0.254", prefix: "10.0.0.0/24", want: false},
+		{start: "0.0.0.0", end: "255.255.255.255", prefix: "0.0.0.0/0", want: true},
+		{start: "10.0.0.0", end: "10.0.255.255", prefix: "::/0", want: false},
+		{start: "2001:db8::", end: "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff", prefix: "2001:db8:1::/48", want: true},
+		{start: "2001:db8::", end: "2001:db8::ffff", prefix: "2001:db8::/64", want: false},
+		{start: "2001:db8::", end: "2001:db8::ffff", prefix: "10.0.0.0/8", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.start+"-"+tt.end+" "+tt.prefix, func(t *testing.T) {
+			r, err := New(netip.MustParseAddr(tt.start), netip.MustParseAddr(tt.end))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := r.ContainsPrefix(netip.MustParsePrefix(tt.prefix)); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end netip.Addr
+	}{
+		{name: "reversed", start: netip.MustParseAddr("10.0.1.0"), end: netip.MustParseAddr("10.0.0.0")},
+		{name: "mixed families", start: netip.MustParseAddr("10.0.0.0"), end: netip.MustParseAddr("2001:db8::")},
+		{name: "zero start", end: netip.MustParseAddr("10.0.0.0")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.start, tt.end); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewUnmaps(t *testing.T) {
+	r, err := New(netip.MustParseAddr("::ffff:10.0.0.0"), netip.MustParseAddr("10.0.255.255"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !r.ContainsPrefix(netip.MustParsePrefix("10.0.1.0/24")) {
+		t.Fatal("expected the mapped range to contain an IPv4 prefix")
+	}
+}
+
+func TestLastAddr(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.0/8":      "10.255.255.255",
+		"192.168.1.77/22": "192.168.3.255",
+		"10.1.2.3/32":     "10.1.2.3",
+		"0.0.0.0/0":       "255.255.255.255",
+		"2001:db8::/120":  "2001:db8::ff",
+	}
+	for prefix, want := range tests {
+		if got := LastAddr(netip.MustParsePrefix(prefix)); got != netip.MustParseAddr(want) {
+			t.Fatalf("LastAddr(%s): expected %s, got %s", prefix, want, got)
+		}
+	}
+}
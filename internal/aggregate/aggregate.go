@@ -0,0 +1,115 @@
+// Package aggregate finds prefixes that can be replaced by a shorter covering prefix.
+package aggregate
+
+import (
+	"cmp"
+	"net/netip"
+	"slices"
+)
+
+// Group is a supernet that covers exactly the addresses of some input prefixes.
+type Group struct {
+	Supernet netip.Prefix
+	// Members are the indices of the input prefixes that Supernet replaces, in
+	// ascending order.
+	Members []int
+}
+
+// Merge finds the groups of prefixes that can be replaced by a single prefix.
+// A prefix that is covered by another input prefix joins the covering prefix's
+// group, and two sibling prefixes (such as 10.0.0.0/25 and 10.0.0.128/25) are
+// merged into their parent, repeatedly, so 10.0.0.0/24 and 10.0.1.0/24 become
+// 10.0.0.0/23. Host bits are ignored. Only groups made of at least two distinct
+// prefixes are returned, sorted by supernet. Invalid prefixes are skipped.
+func Merge(prefixes []netip.Prefix) []Group {
+	members := make(map[netip.Prefix][]int)
+	for i, p := range prefixes {
+		if p.IsValid() {
+			members[p.Masked()] = append(members[p.Masked()], i)
+		}
+	}
+
+	// Fold covered prefixes into the shortest prefix that covers them.
+	set := make(map[netip.Prefix][]int, len(members))
+	for _, p := range sortedByBits(members) {
+		if parent, ok := coveringPrefix(set, p); ok {
+			set[parent] = append(set[parent], members[p]...)
+		} else {
+			set[p] = members[p]
+		}
+	}
+
+	// Merge siblings level by level, longest prefixes first, so merged parents
+	// can merge again with their own siblings.
+	for bits := 128; bits > 0; bits-- {
+		for _, p := range sortedByBits(set) {
+			if p.Bits() != bits {
+				continue
+			}
+			if _, ok := set[p]; !ok {
+				continue // already merged with its sibling
+			}
+			s := sibling(p)
+			if _, ok := set[s]; !ok {
+				continue
+			}
+			parent, _ := p.Addr().Prefix(bits - 1)
+			set[parent] = append(set[p], set[s]...)
+			delete(set, p)
+			delete(set, s)
+		}
+	}
+
+	var groups []Group
+	for supernet, indices := range set {
+		distinct := make(map[netip.Prefix]struct{})
+		for _, i := range indices {
+			distinct[prefixes[i].Masked()] = struct{}{}
+		}
+		if len(distinct) < 2 {
+			continue
+		}
+		slices.Sort(indices)
+		groups = append(groups, Group{Supernet: supernet, Members: indices})
+	}
+	slices.SortFunc(groups, func(a, b Group) int {
+		return comparePrefixes(a.Supernet, b.Supernet)
+	})
+	return groups
+}
+
+// coveringPrefix returns the prefix of set that contains p, if any.
+func coveringPrefix(set map[netip.Prefix][]int, p netip.Prefix) (netip.Prefix, bool) {
+	for bits := 0; bits < p.Bits(); bits++ {
+		parent, _ := p.Addr().Prefix(bits)
+		if _, ok := set[parent]; ok {
+			return parent, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// sibling returns the other half of p's parent prefix. p must have at least one bit.
+func sibling(p netip.Prefix) netip.Prefix {
+	b := p.Addr().AsSlice()
+	i := p.Bits() - 1
+	b[i/8] ^= 0x80 >> (i % 8)
+	addr, _ := netip.AddrFromSlice(b)
+	return netip.PrefixFrom(addr, p.Bits())
+}
+
+// sortedByBits returns the keys of m, shortest prefixes first.
+func sortedByBits[V any](m map[netip.Prefix]V) []netip.Prefix {
+	keys := make([]netip.Prefix, 0, len(m))
+	for p := range m {
+		keys = append(keys, p)
+	}
+	slices.SortFunc(keys, func(a, b netip.Prefix) int {
+		return cmp.Or(cmp.Compare(a.Bits(), b.Bits()), comparePrefixes(a, b))
+	})
+	return keys
+}
+
+func comparePrefixes(a, b netip.Prefix) int {
+	return cmp.Or(a.Addr().Compare(b.Addr()), cmp.Compare(a.Bits(), b.Bits()))
+}
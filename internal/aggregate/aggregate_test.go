@@ -0,0 +1,82 @@
+package aggregate
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func parsePrefixes(t *testing.T, ss ...string) []netip.Prefix {
+	t.Helper()
+	prefixes := make([]netip.Prefix, len(ss))
+	for i, s := range ss {
+		prefixes[i] = netip.MustParsePrefix(s)
+	}
+	return prefixes
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []string
+		want     []Group
+	}{
+		{
+			name:     "siblings",
+			prefixes: []string{"10.0.1.0/24", "10.0.0.0/24"},
+			want:     []Group{{Supernet: netip.MustParsePrefix("10.0.0.0/23"), Members: []int{0, 1}}},
+		},
+		{
+			name:     "cascading siblings",
+			prefixes: []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/23"},
+			want:     []Group{{Supernet: netip.MustParsePrefix("10.0.0.0/22"), Members: []int{0, 1, 2}}},
+		},
+		{
+			name:     "covered prefix",
+			prefixes: []string{"10.1.2.0/24", "10.0.0.0/8", "10.1.0.0/16"},
+			want:     []Group{{Supernet: netip.MustParsePrefix("10.0.0.0/8"), Members: []int{0, 1, 2}}},
+		},
+		{
+			name:     "not adjacent",
+			prefixes: []string{"10.0.1.0/24", "10.0.2.0/24"},
+		},
+		{
+			name:     "duplicates only",
+			prefixes: []string{"10.0.0.0/24", "10.0.0.5/24"},
+		},
+		{
+			name:     "ipv6",
+			prefixes: []string{"2001:db8::/33", "2001:db8:8000::/33", "192.168.0.0/24"},
+			want:     []Group{{Supernet: netip.MustParsePrefix("2001:db8::/32"), Members: []int{0, 1}}},
+		},
+		{
+			name:     "separate groups",
+			prefixes: []string{"192.168.1.0/25", "10.0.0.0/25", "192.168.1.128/25", "10.0.0.128/25"},
+			want: []Group{
+				{Supernet: netip.MustParsePrefix("10.0.0.0/24"), Members: []int{1, 3}},
+				{Supernet: netip.MustParsePrefix("192.168.1.0/24"), Members: []int{0, 2}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Merge(parsePrefixes(t, tt.prefixes...)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSibling(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.0/24":   "10.0.1.0/24",
+		"10.0.1.0/24":   "10.0.0.0/24",
+		"0.0.0.0/1":     "128.0.0.0/1",
+		"2001:db8::/33": "2001:db8:8000::/33",
+	}
+	for in, want := range tests {
+		if got := sibling(netip.MustParsePrefix(in)); got.String() != want {
+			t.Errorf("sibling(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// Package aliasfold normalizes interface aliases for case-insensitive lookup.
+package aliasfold
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Key returns the Unicode simple case-folded form of s. Two strings have the same
+// key exactly when strings.EqualFold reports them equal, which unlike
+// strings.ToLower also holds for runes whose case mappings are not symmetric
+// (for example the Kelvin sign, or the Greek final sigma).
+func Key(s string) string {
+	return strings.Map(fold, s)
+}
+
+// fold maps r to the smallest rune in its simple case-folding orbit.
+func fold(r rune) rune {
+	smallest := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < smallest {
+			smallest = f
+		}
+	}
+	return smallest
+}
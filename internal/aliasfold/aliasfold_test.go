@@ -0,0 +1,42 @@
+package aliasfold
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{name: "ascii", a: "Ethernet 2", b: "ETHERNET 2", same: true},
+		{name: "cyrillic", a: "Подключение по локальной сети", b: "ПОДКЛЮЧЕНИЕ ПО ЛОКАЛЬНОЙ СЕТИ", same: true},
+		{name: "cyrillic mixed", a: "Сеть", b: "сЕТЬ", same: true},
+		{name: "cjk", a: "以太网", b: "以太网", same: true},
+		{name: "cjk with latin", a: "以太网 WLAN", b: "以太网 wlan", same: true},
+		{name: "greek final sigma", a: "ΟΔΟΣ", b: "οδος", same: true},
+		{name: "kelvin sign", a: "K", b: "k", same: true},
+		{name: "different cjk", a: "以太网", b: "以太网 2", same: false},
+		{name: "different cyrillic", a: "Сеть", b: "Сеть 2", same: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Key(tt.a) == Key(tt.b); got != tt.same {
+				t.Fatalf("Key(%q) == Key(%q): expected %v, got %v", tt.a, tt.b, tt.same, got)
+			}
+			if got := strings.EqualFold(tt.a, tt.b); got != tt.same {
+				t.Fatalf("strings.EqualFold(%q, %q) disagrees: %v", tt.a, tt.b, got)
+			}
+		})
+	}
+}
+
+func TestKeyStable(t *testing.T) {
+	for _, s := range []string{"Wi-Fi", "Сеть", "以太网", "ΟΔΟΣ"} {
+		if Key(Key(s)) != Key(s) {
+			t.Fatalf("Key is not idempotent for %q", s)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package bestmatch
+
+import "net/netip"
+
+// Select returns the item whose prefix is the longest match for addr.
+// Ties between equally specific prefixes are broken by the lowest metric; if the
+// metrics are equal as well, the first such item wins. The zone of addr is ignored.
+func Select[T any](
+	items []T,
+	addr netip.Addr,
+	prefix func(T) netip.Prefix,
+	metric func(T) uint32,
+) (best T, ok bool) {
+	addr = addr.WithZone("")
+	bestBits := -1
+	var bestMetric uint32
+
+	for _, item := range items {
+		p := prefix(item)
+		if !p.Contains(addr) {
+			continue
+		}
+		bits, m := p.Bits(), metric(item)
+		if bits > bestBits || (bits == bestBits && m < bestMetric) {
+			best, bestBits, bestMetric, ok = item, bits, m, true
+		}
+	}
+
+	return best, ok
+}
+
+// MostSpecific returns the item with the longest prefix, breaking ties by the
+// lowest metric. count is the number of items sharing that best prefix length
+// and metric, so a count greater than one means the rule could not pick a single
+// item; best is then the first of them. count is zero for an empty slice.
+func MostSpecific[T any](
+	items []T,
+	prefix func(T) netip.Prefix,
+	metric func(T) uint32,
+) (best T, count int) {
+	bestBits := -1
+	var bestMetric uint32
+
+	for _, item := range items {
+		bits, m := prefix(item).Bits(), metric(item)
+		switch {
+		case bits > bestBits || (bits == bestBits && m < bestMetric):
+			best, bestBits, bestMetric, count = item, bits, m, 1
+		case bits == bestBits && m == bestMetric:
+			count++
+		}
+	}
+
+	return best, count
+}
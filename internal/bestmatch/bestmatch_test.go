@@ -0,0 +1,121 @@
+package bestmatch
+
+import (
+	"net/netip"
+	"testing"
+)
+
+type fakeRoute struct {
+	name   string
+	prefix netip.Prefix
+	metric uint32
+}
+
+func selectFake(routes []fakeRoute, addr string) (fakeRoute, bool) {
+	return Select(
+		routes,
+		netip.MustParseAddr(addr),
+		func(r fakeRoute) netip.Prefix { return r.prefix },
+		func(r fakeRoute) uint32 { return r.metric },
+	)
+}
+
+func TestSelect(t *testing.T) {
+	routes := []fakeRoute{
+		{name: "default", prefix: netip.MustParsePrefix("0.0.0.0/0"), metric: 25},
+		{name: "corp", prefix: netip.MustParsePrefix("10.0.0.0/8"), metric: 10},
+		{name: "lab-slow", prefix: netip.MustParsePrefix("10.1.0.0/16"), metric: 50},
+		{name: "lab-fast", prefix: netip.MustParsePrefix("10.1.0.0/16"), metric: 5},
+		{name: "v6-default", prefix: netip.MustParsePrefix("::/0"), metric: 1},
+		{name: "link-local", prefix: netip.MustParsePrefix("fe80::/64"), metric: 256},
+	}
+
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{addr: "8.8.8.8", want: "default"},
+		{addr: "10.9.9.9", want: "corp"},
+		{addr: "10.1.2.3", want: "lab-fast"},
+		{addr: "2001:db8::1", want: "v6-default"},
+		{addr: "fe80::1%5", want: "link-local"},
+	}
+	for _, tt := range tests {
+		got, ok := selectFake(routes, tt.addr)
+		if !ok {
+			t.Fatalf("%s: expected a match", tt.addr)
+		}
+		if got.name != tt.want {
+			t.Fatalf("%s: expected %s, got %s", tt.addr, tt.want, got.name)
+		}
+	}
+}
+
+func TestSelectNoMatch(t *testing.T) {
+	routes := []fakeRoute{{name: "corp", prefix: netip.MustParsePrefix("10.0.0.0/8")}}
+	if _, ok := selectFake(routes, "192.168.1.1"); ok {
+		t.Fatal("expected no match")
+	}
+	if _, ok := selectFake(nil, "192.168.1.1"); ok {
+		t.Fatal("expected no match for empty input")
+	}
+}
+
+func TestMostSpecific(t *testing.T) {
+	p := netip.MustParsePrefix
+	tests := []struct {
+		name      string
+		routes    []fakeRoute
+		want      string
+		wantCount int
+	}{
+		{
+			name: "longest prefix wins",
+			routes: []fakeRoute{
+				{name: "corp", prefix: p("10.0.0.0/8"), metric: 1},
+				{name: "lab", prefix: p("10.1.0.0/16"), metric: 50},
+			},
+			want: "lab", wantCount: 1,
+		},
+		{
+			name: "lowest metric breaks prefix tie",
+			routes: []fakeRoute{
+				{name: "lab-slow", prefix: p("10.1.0.0/16"), metric: 50},
+				{name: "lab-fast", prefix: p("10.1.0.0/16"), metric: 5},
+				{name: "corp", prefix: p("10.0.0.0/8"), metric: 1},
+			},
+			want: "lab-fast", wantCount: 1,
+		},
+		{
+			name: "unbroken tie",
+			routes: []fakeRoute{
+				{name: "a", prefix: p("10.1.0.0/16"), metric: 5},
+				{name: "corp", prefix: p("10.0.0.0/8"), metric: 1},
+				{name: "b", prefix: p("10.2.0.0/16"), metric: 5},
+			},
+			want: "a", wantCount: 2,
+		},
+		{
+			name: "tie reset by a better route",
+			routes: []fakeRoute{
+				{name: "a", prefix: p("10.1.0.0/16"), metric: 5},
+				{name: "b", prefix: p("10.2.0.0/16"), metric: 5},
+				{name: "host", prefix: p("10.1.2.3/32"), metric: 9},
+			},
+			want: "host", wantCount: 1,
+		},
+		{name: "empty", wantCount: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, count := MostSpecific(
+				tt.routes,
+				func(r fakeRoute) netip.Prefix { return r.prefix },
+				func(r fakeRoute) uint32 { return r.metric },
+			)
+			if got.name != tt.want || count != tt.wantCount {
+				t.Fatalf("expected %q (count %d), got %q (count %d)", tt.want, tt.wantCount, got.name, count)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package bounded
+
+import "context"
+
+// Call runs fn in a new goroutine and waits for it to finish or for ctx to be done,
+// whichever comes first.
+//
+// When ctx is done first, Call returns ctx.Err() immediately. fn keeps running in
+// the background until it returns on its own; its result is then discarded.
+func Call[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+
+	if err := ctx.Err(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	// Buffered so the goroutine can always deliver its result and exit,
+	// even after the caller has stopped waiting.
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,56 @@
+package bounded
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCallReturnsResult(t *testing.T) {
+	got, err := Call(context.Background(), func() (int, error) { return 42, nil })
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if got != 42 {
+		t.Fatalf("expected 42, got %d", got)
+	}
+
+	boom := errors.New("boom")
+	if _, err := Call(context.Background(), func() (int, error) { return 0, boom }); !errors.Is(err, boom) {
+		t.Fatalf("expected fn error to be returned, got %v", err)
+	}
+}
+
+func TestCallTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := Call(ctx, func() (int, error) {
+		<-release
+		return 1, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestCallSkipsDoneContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	_, err := Call(ctx, func() (int, error) {
+		called = true
+		return 1, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+	if called {
+		t.Fatal("expected fn not to run for an already-done context")
+	}
+}
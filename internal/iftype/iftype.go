@@ -0,0 +1,28 @@
+// Package iftype names the IANA interface types (ifType) reported by Windows.
+package iftype
+
+import "strconv"
+
+// names covers the interface types that commonly carry routes on Windows.
+var names = map[uint32]string{
+	1:   "Other",
+	6:   "Ethernet",
+	23:  "PPP",
+	24:  "Loopback",
+	37:  "ATM",
+	53:  "Virtual",
+	71:  "Wireless",
+	131: "Tunnel",
+	144: "FireWire",
+	243: "Mobile Broadband",
+	244: "Mobile Broadband",
+}
+
+// Name returns a short readable name for the interface type t, such as
+// "Ethernet" or "Wireless". Types without a name are shown as "Type <t>".
+func Name(t uint32) string {
+	if name, ok := names[t]; ok {
+		return name
+	}
+	return "Type " + strconv.FormatUint(uint64(t), 10)
+}
@@ -0,0 +1,19 @@
+package iftype
+
+import "testing"
+
+func TestName(t *testing.T) {
+	tests := map[uint32]string{
+		6:   "Ethernet",
+		71:  "Wireless",
+		24:  "Loopback",
+		131: "Tunnel",
+		243: "Mobile Broadband",
+		999: "Type 999",
+	}
+	for in, want := range tests {
+		if got := Name(in); got != want {
+			t.Errorf("Name(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
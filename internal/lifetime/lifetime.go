@@ -0,0 +1,60 @@
+package lifetime
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// InfiniteSeconds is the value MIB_IPFORWARD_ROW2 uses for a lifetime that never expires.
+const InfiniteSeconds = math.MaxUint32
+
+// Infinite is the duration reported for a lifetime that never expires.
+const Infinite = time.Duration(math.MaxInt64)
+
+// FromSeconds converts a lifetime read from a route row into a duration.
+func FromSeconds(seconds uint32) time.Duration {
+	if seconds == InfiniteSeconds {
+		return Infinite
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// ToSeconds converts the requested valid and preferred lifetimes into route row values.
+//
+// A zero (or Infinite) duration means the lifetime never expires. Durations are
+// rounded up to whole seconds. When only the valid lifetime is set, the preferred
+// lifetime follows it, since a route cannot stay preferred after it expires.
+func ToSeconds(valid, preferred time.Duration) (validSeconds, preferredSeconds uint32, err error) {
+	validSeconds, err = toSeconds(valid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid valid lifetime: %w", err)
+	}
+	if preferred == 0 {
+		return validSeconds, validSeconds, nil
+	}
+
+	preferredSeconds, err = toSeconds(preferred)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid preferred lifetime: %w", err)
+	}
+	if preferredSeconds > validSeconds {
+		return 0, 0, fmt.Errorf("preferred lifetime %s exceeds valid lifetime %s", preferred, valid)
+	}
+	return validSeconds, preferredSeconds, nil
+}
+
+func toSeconds(d time.Duration) (uint32, error) {
+	if d == 0 || d == Infinite {
+		return InfiniteSeconds, nil
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("lifetime %s is negative", d)
+	}
+
+	seconds := (d + time.Second - 1) / time.Second
+	if seconds >= InfiniteSeconds {
+		return 0, fmt.Errorf("lifetime %s is too long", d)
+	}
+	return uint32(seconds), nil
+}
@@ -0,0 +1,54 @@
+package lifetime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToSeconds(t *testing.T) {
+	tests := []struct {
+		name          string
+		valid         time.Duration
+		preferred     time.Duration
+		wantValid     uint32
+		wantPreferred uint32
+		wantErr       bool
+	}{
+		{name: "default is infinite", wantValid: InfiniteSeconds, wantPreferred: InfiniteSeconds},
+		{name: "explicit infinite", valid: Infinite, preferred: Infinite, wantValid: InfiniteSeconds, wantPreferred: InfiniteSeconds},
+		{name: "preferred follows valid", valid: time.Minute, wantValid: 60, wantPreferred: 60},
+		{name: "both set", valid: time.Hour, preferred: time.Minute, wantValid: 3600, wantPreferred: 60},
+		{name: "rounds up", valid: 1500 * time.Millisecond, wantValid: 2, wantPreferred: 2},
+		{name: "preferred on infinite valid", preferred: time.Minute, wantValid: InfiniteSeconds, wantPreferred: 60},
+		{name: "preferred exceeds valid", valid: time.Minute, preferred: time.Hour, wantErr: true},
+		{name: "negative", valid: -time.Second, wantErr: true},
+		{name: "too long", valid: time.Duration(InfiniteSeconds) * time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, preferred, err := ToSeconds(tt.valid, tt.preferred)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if valid != tt.wantValid || preferred != tt.wantPreferred {
+				t.Fatalf("expected (%d, %d), got (%d, %d)", tt.wantValid, tt.wantPreferred, valid, preferred)
+			}
+		})
+	}
+}
+
+func TestFromSeconds(t *testing.T) {
+	if got := FromSeconds(InfiniteSeconds); got != Infinite {
+		t.Fatalf("expected infinite, got %s", got)
+	}
+	if got := FromSeconds(90); got != 90*time.Second {
+		t.Fatalf("expected 90s, got %s", got)
+	}
+}
@@ -0,0 +1,31 @@
+// Package linkspeed formats interface link speeds reported by Windows.
+package linkspeed
+
+import "strconv"
+
+// Unknown is the value Windows reports when the link speed cannot be determined.
+const Unknown = ^uint64(0)
+
+var units = []struct {
+	bps  uint64
+	name string
+}{
+	{1_000_000_000_000, "Tbps"},
+	{1_000_000_000, "Gbps"},
+	{1_000_000, "Mbps"},
+	{1_000, "Kbps"},
+}
+
+// Format returns bps in the largest unit it reaches, such as "1 Gbps" or
+// "2.5 Gbps". Zero is shown as "unknown".
+func Format(bps uint64) string {
+	if bps == 0 {
+		return "unknown"
+	}
+	for _, u := range units {
+		if bps >= u.bps {
+			return strconv.FormatFloat(float64(bps)/float64(u.bps), 'f', -1, 64) + " " + u.name
+		}
+	}
+	return strconv.FormatUint(bps, 10) + " bps"
+}
@@ -0,0 +1,21 @@
+package linkspeed
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := map[uint64]string{
+		0:                 "unknown",
+		500:               "500 bps",
+		54_000_000:        "54 Mbps",
+		100_000_000:       "100 Mbps",
+		1_000_000_000:     "1 Gbps",
+		2_500_000_000:     "2.5 Gbps",
+		10_000_000_000:    "10 Gbps",
+		1_000_000_000_000: "1 Tbps",
+	}
+	for in, want := range tests {
+		if got := Format(in); got != want {
+			t.Errorf("Format(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Package loopcheck finds cycles in next-hop resolution chains.
+package loopcheck
+
+// Find follows, from every item, the chain item -> next(item) -> ... and reports
+// each cycle it encounters. next returns the item used to reach an item's next
+// hop, or false when the chain ends (an on-link route or an unresolvable next hop).
+// key identifies items; an item revisited within a chain closes a cycle.
+//
+// Chains longer than maxDepth are abandoned without reporting. Each cycle is
+// reported once, as the sequence of items starting at the first one reached, in
+// the order the chain visits them.
+func Find[T any, K comparable](items []T, next func(T) (T, bool), key func(T) K, maxDepth int) [][]T {
+	var cycles [][]T
+	reported := make(map[K]bool)
+
+	for _, start := range items {
+		chain := []T{start}
+		position := map[K]int{key(start): 0}
+		current := start
+		for depth := 0; depth < maxDepth; depth++ {
+			n, ok := next(current)
+			if !ok {
+				break
+			}
+			k := key(n)
+			if i, seen := position[k]; seen {
+				cycle := chain[i:]
+				if !reported[key(cycle[0])] {
+					for _, item := range cycle {
+						reported[key(item)] = true
+					}
+					cycles = append(cycles, cycle)
+				}
+				break
+			}
+			position[k] = len(chain)
+			chain = append(chain, n)
+			current = n
+		}
+	}
+	return cycles
+}
@@ -0,0 +1,73 @@
+package loopcheck
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []string
+		edges    map[string]string
+		maxDepth int
+		want     [][]string
+	}{
+		{
+			name:     "chain ends on-link",
+			items:    []string{"a", "b", "c"},
+			edges:    map[string]string{"a": "b", "b": "c"},
+			maxDepth: 8,
+		},
+		{
+			name:     "self loop",
+			items:    []string{"a", "b"},
+			edges:    map[string]string{"a": "a", "b": "a"},
+			maxDepth: 8,
+			want:     [][]string{{"a"}},
+		},
+		{
+			name:     "two-route loop reported once",
+			items:    []string{"a", "b", "c"},
+			edges:    map[string]string{"a": "b", "b": "a", "c": "a"},
+			maxDepth: 8,
+			want:     [][]string{{"a", "b"}},
+		},
+		{
+			name:     "loop reached through a tail",
+			items:    []string{"x", "a", "b", "c"},
+			edges:    map[string]string{"x": "a", "a": "b", "b": "c", "c": "a"},
+			maxDepth: 8,
+			want:     [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:     "independent loops",
+			items:    []string{"a", "b", "c", "d"},
+			edges:    map[string]string{"a": "b", "b": "a", "c": "d", "d": "c"},
+			maxDepth: 8,
+			want:     [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			name:     "depth limit",
+			items:    []string{"a"},
+			edges:    map[string]string{"a": "b", "b": "c", "c": "a"},
+			maxDepth: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Find(
+				tt.items,
+				func(item string) (string, bool) {
+					n, ok := tt.edges[item]
+					return n, ok
+				},
+				func(item string) string { return item },
+				tt.maxDepth,
+			)
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
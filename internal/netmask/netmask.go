@@ -0,0 +1,70 @@
+// Package netmask converts between dotted netmasks and prefix lengths.
+package netmask
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"net/netip"
+)
+
+// Bits returns the prefix length of a contiguous netmask such as 255.255.0.0.
+// IPv4-mapped IPv6 masks are treated as IPv4.
+func Bits(mask netip.Addr) (int, error) {
+	if !mask.IsValid() {
+		return 0, errors.New("invalid netmask")
+	}
+	mask = mask.Unmap()
+
+	ones := 0
+	seenZero := false
+	for _, b := range mask.AsSlice() {
+		if seenZero && b != 0 {
+			return 0, fmt.Errorf("netmask %s is not contiguous", mask)
+		}
+		n := bits.LeadingZeros8(^b)
+		if b<<n != 0 {
+			return 0, fmt.Errorf("netmask %s is not contiguous", mask)
+		}
+		ones += n
+		seenZero = n < 8
+	}
+	return ones, nil
+}
+
+// ToPrefix combines a network address and a netmask of the same family into a
+// prefix. Host bits set in network (beyond the mask) are rejected, as route.exe does.
+func ToPrefix(network, mask netip.Addr) (netip.Prefix, error) {
+	if !network.IsValid() {
+		return netip.Prefix{}, errors.New("invalid network address")
+	}
+	network = network.Unmap()
+	ones, err := Bits(mask)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if network.Is4() != mask.Unmap().Is4() {
+		return netip.Prefix{}, fmt.Errorf("network %s and netmask %s are of different address families", network, mask)
+	}
+
+	prefix := netip.PrefixFrom(network, ones)
+	if prefix.Masked().Addr() != network {
+		return netip.Prefix{}, fmt.Errorf("network %s has bits set outside netmask %s", network, mask)
+	}
+	return prefix, nil
+}
+
+// Mask returns the netmask of prefix as an address of the prefix's family,
+// e.g. 255.255.240.0 for 10.0.0.0/20 or ffff:ffff:: for 2001:db8::/32.
+// The zero Addr is returned for an invalid prefix.
+func Mask(prefix netip.Prefix) netip.Addr {
+	if !prefix.IsValid() {
+		return netip.Addr{}
+	}
+	b := make([]byte, prefix.Addr().BitLen()/8)
+	for i := range prefix.Bits() {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	mask, _ := netip.AddrFromSlice(b)
+	return mask
+}
@@ -0,0 +1,79 @@
+package netmask
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestToPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		mask    string
+		want    string
+		wantErr bool
+	}{
+		{name: "class A", network: "10.0.0.0", mask: "255.0.0.0", want: "10.0.0.0/8"},
+		{name: "non-octet boundary", network: "192.168.4.0", mask: "255.255.252.0", want: "192.168.4.0/22"},
+		{name: "host route", network: "10.1.2.3", mask: "255.255.255.255", want: "10.1.2.3/32"},
+		{name: "default route", network: "0.0.0.0", mask: "0.0.0.0", want: "0.0.0.0/0"},
+		{name: "mapped mask", network: "10.0.0.0", mask: "::ffff:255.0.0.0", want: "10.0.0.0/8"},
+		{name: "ipv6", network: "2001:db8::", mask: "ffff:ffff::", want: "2001:db8::/32"},
+		{name: "non-contiguous", network: "10.0.0.0", mask: "255.0.255.0", wantErr: true},
+		{name: "hole within octet", network: "10.0.0.0", mask: "255.160.0.0", wantErr: true},
+		{name: "host bits set", network: "10.0.0.5", mask: "255.0.0.0", wantErr: true},
+		{name: "family mismatch", network: "2001:db8::", mask: "255.0.0.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToPrefix(netip.MustParseAddr(tt.network), netip.MustParseAddr(tt.mask))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := netip.MustParsePrefix(tt.want); got != want {
+				t.Fatalf("expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestToPrefixInvalid(t *testing.T) {
+	if _, err := ToPrefix(netip.Addr{}, netip.MustParseAddr("255.0.0.0")); err == nil {
+		t.Fatal("expected an error for an invalid network address")
+	}
+	if _, err := ToPrefix(netip.MustParseAddr("10.0.0.0"), netip.Addr{}); err == nil {
+		t.Fatal("expected an error for an invalid netmask")
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.0/8":     "255.0.0.0",
+		"192.168.4.0/22": "255.255.252.0",
+		"10.1.2.3/32":    "255.255.255.255",
+		"0.0.0.0/0":      "0.0.0.0",
+		"2001:db8::/32":  "ffff:ffff::",
+		"fe80::/10":      "ffc0::",
+		"::/0":           "::",
+	}
+	for prefix, want := range tests {
+		got := Mask(netip.MustParsePrefix(prefix))
+		if got != netip.MustParseAddr(want) {
+			t.Fatalf("Mask(%s): expected %s, got %s", prefix, want, got)
+		}
+		// Mask and ToPrefix are inverses for canonical prefixes.
+		back, err := ToPrefix(netip.MustParsePrefix(prefix).Addr(), got)
+		if err != nil || back != netip.MustParsePrefix(prefix) {
+			t.Fatalf("ToPrefix(Mask(%s)): got %s, %v", prefix, back, err)
+		}
+	}
+	if Mask(netip.Prefix{}).IsValid() {
+		t.Fatal("expected the zero Addr for an invalid prefix")
+	}
+}
@@ -0,0 +1,90 @@
+// Package notes persists free-form notes about routes in a JSON sidecar file,
+// since Windows routes cannot carry comments themselves.
+package notes
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Key identifies the route a note belongs to.
+type Key struct {
+	Destination    netip.Prefix
+	NextHop        netip.Addr
+	InterfaceIndex uint32
+}
+
+type entry struct {
+	Destination    netip.Prefix `json:"destination"`
+	NextHop        netip.Addr   `json:"next_hop"`
+	InterfaceIndex uint32       `json:"interface_index"`
+	Note           string       `json:"note"`
+}
+
+// Load reads the notes stored at path. A missing file yields an empty map.
+func Load(path string) (map[Key]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return make(map[Key]string), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid notes file %s: %w", path, err)
+	}
+	notes := make(map[Key]string, len(entries))
+	for _, e := range entries {
+		notes[Key{e.Destination, e.NextHop, e.InterfaceIndex}] = e.Note
+	}
+	return notes, nil
+}
+
+// Save writes notes to path, creating its directory if needed. Entries are
+// sorted so the file diffs cleanly, and the file is replaced atomically so a
+// failed write never leaves a truncated store behind.
+func Save(path string, notes map[Key]string) error {
+	entries := make([]entry, 0, len(notes))
+	for key, note := range notes {
+		entries = append(entries, entry{key.Destination, key.NextHop, key.InterfaceIndex, note})
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		return cmp.Or(
+			a.Destination.Addr().Compare(b.Destination.Addr()),
+			cmp.Compare(a.Destination.Bits(), b.Destination.Bits()),
+			a.NextHop.Compare(b.NextHop),
+			cmp.Compare(a.InterfaceIndex, b.InterfaceIndex),
+		)
+	})
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,61 @@
+package notes
+
+import (
+	"maps"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	notes, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notes) != 0 {
+		t.Fatalf("expected no notes, got %v", notes)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "winroute", "notes.json")
+	want := map[Key]string{
+		{netip.MustParsePrefix("10.20.0.0/16"), netip.MustParseAddr("192.168.1.1"), 5}: "office VPN",
+		{netip.MustParsePrefix("10.20.0.0/16"), netip.MustParseAddr("10.0.0.1"), 7}:    "备用线路",
+		{netip.MustParsePrefix("2001:db8::/32"), netip.MustParseAddr("::"), 12}:        "lab",
+	}
+	if err := Save(path, want); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !maps.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	delete(want, Key{netip.MustParsePrefix("2001:db8::/32"), netip.MustParseAddr("::"), 12})
+	if err := Save(path, want); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := Load(path); !maps.Equal(got, want) {
+		t.Fatalf("expected %v after removal, got %v", want, got)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("expected only the notes file, found %d entries", len(entries))
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected an error for an invalid file")
+	}
+}
@@ -0,0 +1,31 @@
+// Package pace spaces out repeated operations.
+package pace
+
+import "time"
+
+// Pacer makes successive calls to Wait return at least interval apart.
+type Pacer struct {
+	interval time.Duration
+	next     time.Time
+	sleep    func(time.Duration)
+	now      func() time.Time
+}
+
+// New returns a Pacer for the given interval. A non-positive interval never waits.
+func New(interval time.Duration) *Pacer {
+	return &Pacer{interval: interval, sleep: time.Sleep, now: time.Now}
+}
+
+// Wait returns immediately on the first call and afterwards blocks until
+// interval has passed since the previous call returned.
+func (p *Pacer) Wait() {
+	if p.interval <= 0 {
+		return
+	}
+	now := p.now()
+	if now.Before(p.next) {
+		p.sleep(p.next.Sub(now))
+		now = p.next
+	}
+	p.next = now.Add(p.interval)
+}
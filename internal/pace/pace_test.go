@@ -0,0 +1,36 @@
+package pace
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPacerWait(t *testing.T) {
+	clock := time.Unix(0, 0)
+	var slept []time.Duration
+	p := New(100 * time.Millisecond)
+	p.now = func() time.Time { return clock }
+	p.sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		clock = clock.Add(d)
+	}
+
+	p.Wait() // first call does not wait
+	clock = clock.Add(30 * time.Millisecond)
+	p.Wait() // waits for the rest of the interval
+	clock = clock.Add(250 * time.Millisecond)
+	p.Wait() // interval already passed
+
+	if want := []time.Duration{70 * time.Millisecond}; !slices.Equal(slept, want) {
+		t.Fatalf("expected sleeps %v, got %v", want, slept)
+	}
+}
+
+func TestPacerNoInterval(t *testing.T) {
+	p := New(0)
+	p.sleep = func(time.Duration) { t.Fatal("unexpected sleep") }
+	for range 3 {
+		p.Wait()
+	}
+}
@@ -0,0 +1,79 @@
+// Package persistroute parses the IPv4 persistent routes that "route -p add"
+// records in the registry and maps them to the interface they will be
+// restored on.
+//
+// Each route is a value under Key whose name has the form
+// "destination,netmask,gateway,metric", e.g. "10.0.0.0,255.0.0.0,192.168.1.1,1".
+// The value data is unused. IPv6 persistent routes are not kept there.
+package persistroute
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/bnkrr/winroute/internal/netmask"
+)
+
+// Key is the registry key holding the persistent routes, relative to
+// HKEY_LOCAL_MACHINE.
+const Key = `SYSTEM\CurrentControlSet\Services\Tcpip\Parameters\PersistentRoutes`
+
+// Entry is one persistent route.
+type Entry struct {
+	Destination netip.Prefix
+	// Gateway is 0.0.0.0 for an on-link route.
+	Gateway netip.Addr
+	Metric  uint32
+}
+
+// Parse parses a value name of the form "destination,netmask,gateway,metric".
+func Parse(name string) (Entry, error) {
+	fields := strings.Split(name, ",")
+	if len(fields) != 4 {
+		return Entry{}, fmt.Errorf("persistent route %q: expected 4 comma-separated fields, got %d", name, len(fields))
+	}
+	network, err := netip.ParseAddr(strings.TrimSpace(fields[0]))
+	if err != nil || !network.Is4() {
+		return Entry{}, fmt.Errorf("persistent route %q: invalid destination %q", name, fields[0])
+	}
+	mask, err := netip.ParseAddr(strings.TrimSpace(fields[1]))
+	if err != nil || !mask.Is4() {
+		return Entry{}, fmt.Errorf("persistent route %q: invalid netmask %q", name, fields[1])
+	}
+	destination, err := netmask.ToPrefix(network, mask)
+	if err != nil {
+		return Entry{}, fmt.Errorf("persistent route %q: %w", name, err)
+	}
+	gateway, err := netip.ParseAddr(strings.TrimSpace(fields[2]))
+	if err != nil || !gateway.Is4() {
+		return Entry{}, fmt.Errorf("persistent route %q: invalid gateway %q", name, fields[2])
+	}
+	metric, err := strconv.ParseUint(strings.TrimSpace(fields[3]), 10, 32)
+	if err != nil {
+		return Entry{}, fmt.Errorf("persistent route %q: invalid metric %q", name, fields[3])
+	}
+	return Entry{Destination: destination, Gateway: gateway, Metric: uint32(metric)}, nil
+}
+
+// SelectInterface returns the position in interfaces of the interface the
+// route will be restored on: the first one with an address whose on-link
+// prefix contains the gateway or, for an on-link route, the destination
+// address. Each element of interfaces holds one interface's unicast
+// addresses. It returns -1 when no interface matches, e.g. because the
+// adapter the route was added on is disconnected.
+func SelectInterface(e Entry, interfaces [][]netip.Prefix) int {
+	target := e.Gateway
+	if !target.IsValid() || target.IsUnspecified() {
+		target = e.Destination.Addr()
+	}
+	for i, addresses := range interfaces {
+		for _, address := range addresses {
+			if address.Addr().Is4() && address.Masked().Contains(target) {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,86 @@
+package persistroute
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		want Entry
+	}{
+		{
+			name: "10.0.0.0,255.0.0.0,192.168.1.1,1",
+			want: Entry{Destination: netip.MustParsePrefix("10.0.0.0/8"), Gateway: netip.MustParseAddr("192.168.1.1"), Metric: 1},
+		},
+		{
+			name: "0.0.0.0,0.0.0.0,10.0.0.1,256",
+			want: Entry{Destination: netip.MustParsePrefix("0.0.0.0/0"), Gateway: netip.MustParseAddr("10.0.0.1"), Metric: 256},
+		},
+		{
+			name: "172.16.5.0,255.255.255.0,0.0.0.0,0",
+			want: Entry{Destination: netip.MustParsePrefix("172.16.5.0/24"), Gateway: netip.MustParseAddr("0.0.0.0")},
+		},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.name)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Fatalf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, name := range []string{
+		"",
+		"10.0.0.0,255.0.0.0,192.168.1.1",
+		"10.0.0.0,255.0.0.0,192.168.1.1,1,extra",
+		"10.0.0.1,255.0.0.0,192.168.1.1,1",
+		"10.0.0.0,255.0.255.0,192.168.1.1,1",
+		"2001:db8::,ffff::,::,1",
+		"10.0.0.0,255.0.0.0,gateway,1",
+		"10.0.0.0,255.0.0.0,192.168.1.1,-1",
+	} {
+		if _, err := Parse(name); err == nil {
+			t.Fatalf("%q: expected an error", name)
+		}
+	}
+}
+
+func TestSelectInterface(t *testing.T) {
+	interfaces := [][]netip.Prefix{
+		{netip.MustParsePrefix("127.0.0.1/8")},
+		{netip.MustParsePrefix("fe80::1/64"), netip.MustParsePrefix("192.168.1.10/24")},
+		{netip.MustParsePrefix("10.0.0.5/8")},
+	}
+	tests := []struct {
+		name  string
+		entry Entry
+		want  int
+	}{
+		{
+			name:  "gateway on second interface",
+			entry: Entry{Destination: netip.MustParsePrefix("172.16.0.0/12"), Gateway: netip.MustParseAddr("192.168.1.1")},
+			want:  1,
+		},
+		{
+			name:  "on-link route matches destination",
+			entry: Entry{Destination: netip.MustParsePrefix("10.20.0.0/16"), Gateway: netip.MustParseAddr("0.0.0.0")},
+			want:  2,
+		},
+		{
+			name:  "unreachable gateway",
+			entry: Entry{Destination: netip.MustParsePrefix("0.0.0.0/0"), Gateway: netip.MustParseAddr("172.16.0.1")},
+			want:  -1,
+		},
+	}
+	for _, tt := range tests {
+		if got := SelectInterface(tt.entry, interfaces); got != tt.want {
+			t.Fatalf("%s: expected %d, got %d", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package poll
+
+import (
+	"context"
+	"time"
+)
+
+// Until calls cond immediately and then every interval until it reports true,
+// returns an error, or ctx is done. In the last case ctx.Err() is returned.
+func Until(ctx context.Context, interval time.Duration, cond func() (bool, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		done, err := cond()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUntilSucceeds(t *testing.T) {
+	calls := 0
+	err := Until(context.Background(), time.Millisecond, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestUntilReturnsCondError(t *testing.T) {
+	boom := errors.New("boom")
+	err := Until(context.Background(), time.Millisecond, func() (bool, error) {
+		return false, boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected cond error, got %v", err)
+	}
+}
+
+func TestUntilTimesOut(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := Until(ctx, time.Millisecond, func() (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package retry
+
+import "time"
+
+// sleep is replaced in tests.
+var sleep = time.Sleep
+
+// Do calls fn up to attempts times, waiting backoff between calls, for as long as
+// fn fails with an error that transient reports as retryable. It returns the
+// result of the last call. An attempts value below 1 is treated as 1.
+func Do(attempts int, backoff time.Duration, transient func(error) bool, fn func() error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			sleep(backoff)
+		}
+		err = fn()
+		if err == nil || !transient(err) {
+			return err
+		}
+	}
+	return err
+}
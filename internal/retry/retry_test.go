@@ -0,0 +1,87 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	errTransient = errors.New("not ready")
+	errPermanent = errors.New("access denied")
+)
+
+func isTransient(err error) bool {
+	return errors.Is(err, errTransient)
+}
+
+func withoutSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var slept []time.Duration
+	sleep = func(d time.Duration) { slept = append(slept, d) }
+	t.Cleanup(func() { sleep = time.Sleep })
+	return &slept
+}
+
+func TestDoRetriesTransientErrors(t *testing.T) {
+	slept := withoutSleep(t)
+
+	calls := 0
+	err := Do(3, time.Second, isTransient, func() error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected success on third attempt, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+	if len(*slept) != 2 || (*slept)[0] != time.Second {
+		t.Fatalf("expected two 1s waits, got %v", *slept)
+	}
+}
+
+func TestDoStopsOnPermanentError(t *testing.T) {
+	withoutSleep(t)
+
+	calls := 0
+	err := Do(5, time.Second, isTransient, func() error {
+		calls++
+		return errPermanent
+	})
+	if !errors.Is(err, errPermanent) {
+		t.Fatalf("expected permanent error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single call, got %d", calls)
+	}
+}
+
+func TestDoGivesUpAfterAttempts(t *testing.T) {
+	withoutSleep(t)
+
+	calls := 0
+	err := Do(2, time.Second, isTransient, func() error {
+		calls++
+		return errTransient
+	})
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("expected last transient error, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+
+	calls = 0
+	Do(0, time.Second, isTransient, func() error {
+		calls++
+		return errTransient
+	})
+	if calls != 1 {
+		t.Fatalf("expected attempts below 1 to call once, got %d", calls)
+	}
+}
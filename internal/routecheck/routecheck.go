@@ -0,0 +1,156 @@
+package routecheck
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// Kind identifies the kind of problem an Issue reports.
+type Kind int
+
+const (
+	// KindDuplicate marks routes sharing destination, next hop and interface.
+	KindDuplicate Kind = iota
+	// KindInterfaceDown marks a route on an interface that is not up.
+	KindInterfaceDown
+	// KindDefaultRouteTie marks default routes of one family with equal metrics.
+	KindDefaultRouteTie
+	// KindNextHopUnreachable marks a route whose next hop is not on-link on its interface.
+	KindNextHopUnreachable
+)
+
+// Entry is the subset of route data the checks need.
+type Entry struct {
+	Destination        netip.Prefix
+	NextHop            netip.Addr
+	Metric             uint32
+	InterfaceIndex     uint32
+	InterfaceUp        bool
+	InterfaceAddresses []netip.Prefix
+	// System marks routes Windows manages itself; they are exempt from the
+	// interface and next-hop checks.
+	System bool
+}
+
+// Issue is one problem found by Check. Entries holds indices into the checked slice.
+type Issue struct {
+	Kind        Kind
+	Entries     []int
+	Description string
+}
+
+// OnLink reports whether addr lies inside one of the on-link prefixes of addresses.
+func OnLink(addr netip.Addr, addresses []netip.Prefix) bool {
+	addr = addr.WithZone("")
+	for _, address := range addresses {
+		if address.Masked().Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasGateway reports whether nextHop names a gateway rather than an on-link route.
+func HasGateway(nextHop netip.Addr) bool {
+	return nextHop.IsValid() && !nextHop.IsUnspecified()
+}
+
+type identity struct {
+	destination netip.Prefix
+	nextHop     netip.Addr
+	ifaceIndex  uint32
+}
+
+type defaultRouteKey struct {
+	is4    bool
+	metric uint32
+}
+
+// Check scans entries and reports duplicates, routes on down interfaces, default
+// routes that tie on metric, and next hops that are not on-link. Issues are
+// reported in the order of the first entry involved.
+func Check(entries []Entry) []Issue {
+	var issues []Issue
+
+	byIdentity := make(map[identity][]int)
+	var identities []identity
+	defaults := make(map[defaultRouteKey][]int)
+	var defaultKeys []defaultRouteKey
+
+	for i, e := range entries {
+		id := identity{destination: e.Destination, nextHop: e.NextHop, ifaceIndex: e.InterfaceIndex}
+		if _, seen := byIdentity[id]; !seen {
+			identities = append(identities, id)
+		}
+		byIdentity[id] = append(byIdentity[id], i)
+
+		if e.Destination.Bits() == 0 {
+			key := defaultRouteKey{is4: e.Destination.Addr().Is4(), metric: e.Metric}
+			if _, seen := defaults[key]; !seen {
+				defaultKeys = append(defaultKeys, key)
+			}
+			defaults[key] = append(defaults[key], i)
+		}
+
+		if e.System {
+			continue
+		}
+		if !e.InterfaceUp {
+			issues = append(issues, Issue{
+				Kind:        KindInterfaceDown,
+				Entries:     []int{i},
+				Description: fmt.Sprintf("route to %s uses interface %d, which is not up", e.Destination, e.InterfaceIndex),
+			})
+		}
+		if HasGateway(e.NextHop) && !OnLink(e.NextHop, e.InterfaceAddresses) {
+			issues = append(issues, Issue{
+				Kind:        KindNextHopUnreachable,
+				Entries:     []int{i},
+				Description: fmt.Sprintf("next hop %s of route to %s is not on-link on interface %d", e.NextHop, e.Destination, e.InterfaceIndex),
+			})
+		}
+	}
+
+	for _, id := range identities {
+		if indices := byIdentity[id]; len(indices) > 1 {
+			issues = append(issues, Issue{
+				Kind:        KindDuplicate,
+				Entries:     indices,
+				Description: fmt.Sprintf("%d duplicate routes to %s via %s on interface %d", len(indices), id.destination, id.nextHop, id.ifaceIndex),
+			})
+		}
+	}
+
+	for _, key := range defaultKeys {
+		indices := distinctInterfaces(entries, defaults[key])
+		if len(indices) > 1 {
+			family := "IPv6"
+			if key.is4 {
+				family = "IPv4"
+			}
+			issues = append(issues, Issue{
+				Kind:        KindDefaultRouteTie,
+				Entries:     indices,
+				Description: fmt.Sprintf("%d %s default routes share metric %d", len(indices), family, key.metric),
+			})
+		}
+	}
+
+	return issues
+}
+
+// distinctInterfaces keeps the first entry per interface, so exact duplicates are
+// left to the duplicate check.
+func distinctInterfaces(entries []Entry, indices []int) []int {
+	seen := make(map[identity]struct{}, len(indices))
+	var distinct []int
+	for _, i := range indices {
+		id := identity{nextHop: entries[i].NextHop, ifaceIndex: entries[i].InterfaceIndex}
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		distinct = append(distinct, i)
+	}
+	return distinct
+}
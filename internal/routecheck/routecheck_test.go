@@ -0,0 +1,93 @@
+package routecheck
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+var lanAddresses = []netip.Prefix{netip.MustParsePrefix("192.168.1.10/24")}
+
+func entry(dest, nextHop string, metric, index uint32) Entry {
+	return Entry{
+		Destination:        netip.MustParsePrefix(dest),
+		NextHop:            netip.MustParseAddr(nextHop),
+		Metric:             metric,
+		InterfaceIndex:     index,
+		InterfaceUp:        true,
+		InterfaceAddresses: lanAddresses,
+	}
+}
+
+func kinds(t *testing.T, issues []Issue) map[Kind][][]int {
+	t.Helper()
+	got := make(map[Kind][][]int)
+	for _, issue := range issues {
+		got[issue.Kind] = append(got[issue.Kind], issue.Entries)
+		if issue.Description == "" {
+			t.Errorf("issue %v on entries %v has no description", issue.Kind, issue.Entries)
+		}
+	}
+	return got
+}
+
+func TestCheckHealthyTable(t *testing.T) {
+	entries := []Entry{
+		entry("0.0.0.0/0", "192.168.1.1", 25, 5),
+		entry("192.168.1.0/24", "0.0.0.0", 256, 5),
+		entry("10.0.0.0/8", "192.168.1.254", 10, 5),
+	}
+	if issues := Check(entries); len(issues) != 0 {
+		t.Fatalf("expected no issues, got %+v", issues)
+	}
+}
+
+func TestCheckFindsIssues(t *testing.T) {
+	down := entry("172.16.0.0/12", "0.0.0.0", 10, 7)
+	down.InterfaceUp = false
+	systemDown := entry("224.0.0.0/4", "0.0.0.0", 256, 7)
+	systemDown.InterfaceUp = false
+	systemDown.System = true
+
+	entries := []Entry{
+		entry("0.0.0.0/0", "192.168.1.1", 25, 5),   // 0
+		entry("0.0.0.0/0", "192.168.1.2", 25, 6),   // 1: default tie with 0
+		entry("10.0.0.0/8", "192.168.1.254", 1, 5), // 2
+		entry("10.0.0.0/8", "192.168.1.254", 1, 5), // 3: duplicate of 2
+		entry("10.9.0.0/16", "10.1.1.1", 1, 5),     // 4: unreachable next hop
+		down,                                       // 5: interface down
+		systemDown,                                 // 6: exempt
+		entry("::/0", "fe80::1%5", 25, 5),          // 7: v6 link-local not on-link
+	}
+
+	got := kinds(t, Check(entries))
+	if want := [][]int{{2, 3}}; !reflect.DeepEqual(got[KindDuplicate], want) {
+		t.Fatalf("expected duplicates %v, got %v", want, got[KindDuplicate])
+	}
+	if want := [][]int{{0, 1}}; !reflect.DeepEqual(got[KindDefaultRouteTie], want) {
+		t.Fatalf("expected default tie %v, got %v", want, got[KindDefaultRouteTie])
+	}
+	if want := [][]int{{5}}; !reflect.DeepEqual(got[KindInterfaceDown], want) {
+		t.Fatalf("expected interface down %v, got %v", want, got[KindInterfaceDown])
+	}
+	if want := [][]int{{4}, {7}}; !reflect.DeepEqual(got[KindNextHopUnreachable], want) {
+		t.Fatalf("expected unreachable %v, got %v", want, got[KindNextHopUnreachable])
+	}
+}
+
+func TestOnLink(t *testing.T) {
+	addresses := []netip.Prefix{
+		netip.MustParsePrefix("192.168.1.10/24"),
+		netip.MustParsePrefix("fe80::1234/64"),
+	}
+	for addr, want := range map[string]bool{
+		"192.168.1.1": true,
+		"192.168.2.1": false,
+		"fe80::1%5":   true,
+		"2001:db8::1": false,
+	} {
+		if got := OnLink(netip.MustParseAddr(addr), addresses); got != want {
+			t.Errorf("OnLink(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package routeclass
+
+import "net/netip"
+
+var (
+	loopbackV4  = netip.MustParsePrefix("127.0.0.0/8")
+	loopbackV6  = netip.MustParsePrefix("::1/128")
+	multicastV4 = netip.MustParsePrefix("224.0.0.0/4")
+	multicastV6 = netip.MustParsePrefix("ff00::/8")
+	linkLocalV4 = netip.MustParsePrefix("169.254.0.0/16")
+	linkLocalV6 = netip.MustParsePrefix("fe80::/10")
+	uniqueLocal = netip.MustParsePrefix("fc00::/7")
+	broadcastV4 = netip.MustParsePrefix("255.255.255.255/32")
+)
+
+// within reports whether prefix lies entirely inside block.
+func within(prefix, block netip.Prefix) bool {
+	return prefix.Bits() >= block.Bits() && block.Contains(prefix.Addr())
+}
+
+// IsLoopback reports whether destination is inside 127.0.0.0/8 or is ::1/128.
+func IsLoopback(destination netip.Prefix) bool {
+	return within(destination, loopbackV4) || within(destination, loopbackV6)
+}
+
+// IsMulticast reports whether destination is inside 224.0.0.0/4 or ff00::/8.
+func IsMulticast(destination netip.Prefix) bool {
+	return within(destination, multicastV4) || within(destination, multicastV6)
+}
+
+// IsLinkLocal reports whether destination is inside 169.254.0.0/16 or fe80::/10.
+func IsLinkLocal(destination netip.Prefix) bool {
+	return within(destination, linkLocalV4) || within(destination, linkLocalV6)
+}
+
+// IsUniqueLocal reports whether destination is inside the IPv6 unique local
+// address block fc00::/7 (RFC 4193).
+func IsUniqueLocal(destination netip.Prefix) bool {
+	return within(destination, uniqueLocal)
+}
+
+// IsBroadcast reports whether destination is the limited broadcast address
+// 255.255.255.255/32, or the /32 directed broadcast address of one of the given
+// interface addresses (e.g. 192.168.1.255/32 for 192.168.1.10/24).
+func IsBroadcast(destination netip.Prefix, interfaceAddresses []netip.Prefix) bool {
+	if destination == broadcastV4 {
+		return true
+	}
+	if !destination.Addr().Is4() || destination.Bits() != 32 {
+		return false
+	}
+	for _, address := range interfaceAddresses {
+		if !address.Addr().Is4() || address.Bits() >= 31 {
+			continue
+		}
+		if lastAddr(address.Masked()) == destination.Addr() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSystem reports whether destination is a route Windows installs for its own
+// use: loopback, multicast, broadcast or link-local.
+func IsSystem(destination netip.Prefix, interfaceAddresses []netip.Prefix) bool {
+	return IsLoopback(destination) ||
+		IsMulticast(destination) ||
+		IsLinkLocal(destination) ||
+		IsBroadcast(destination, interfaceAddresses)
+}
+
+// lastAddr returns the highest address in an IPv4 prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	a := prefix.Addr().As4()
+	hostBits := 32 - prefix.Bits()
+	v := uint32(a[0])<<24 | uint32(a[1])<<16 | uint32(a[2])<<8 | uint32(a[3])
+	v |= uint32(1)<<hostBits - 1
+	return netip.AddrFrom4([4]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+}
+
+// IsBlackhole reports whether a route to destination that leaves through the
+// software loopback interface discards traffic. Windows has no dedicated
+// blackhole route type; the loopback interface carries only loopback, multicast
+// and broadcast routes of its own, so any other destination routed there never
+// reaches the network.
+func IsBlackhole(destination netip.Prefix, loopbackInterface bool, interfaceAddresses []netip.Prefix) bool {
+	return loopbackInterface && !IsSystem(destination, interfaceAddresses)
+}
@@ -0,0 +1,120 @@
+package routeclass
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestIsSystem(t *testing.T) {
+	addresses := []netip.Prefix{
+		netip.MustParsePrefix("192.168.1.10/24"),
+		netip.MustParsePrefix("10.0.0.5/8"),
+		netip.MustParsePrefix("2001:db8::10/64"),
+	}
+
+	tests := []struct {
+		destination string
+		want        bool
+	}{
+		{destination: "127.0.0.0/8", want: true},
+		{destination: "127.0.0.1/32", want: true},
+		{destination: "127.255.255.255/32", want: true},
+		{destination: "::1/128", want: true},
+		{destination: "224.0.0.0/4", want: true},
+		{destination: "239.255.255.250/32", want: true},
+		{destination: "ff00::/8", want: true},
+		{destination: "ff02::1/128", want: true},
+		{destination: "255.255.255.255/32", want: true},
+		{destination: "192.168.1.255/32", want: true},
+		{destination: "10.255.255.255/32", want: true},
+		{destination: "169.254.0.0/16", want: true},
+		{destination: "fe80::/64", want: true},
+		{destination: "0.0.0.0/0", want: false},
+		{destination: "::/0", want: false},
+		{destination: "128.0.0.0/1", want: false},
+		{destination: "192.168.1.0/24", want: false},
+		{destination: "192.168.1.10/32", want: false},
+		{destination: "192.168.2.255/32", want: false},
+		{destination: "240.0.0.0/4", want: false},
+		{destination: "2001:db8::/64", want: false},
+	}
+	for _, tt := range tests {
+		if got := IsSystem(netip.MustParsePrefix(tt.destination), addresses); got != tt.want {
+			t.Errorf("IsSystem(%s) = %v, want %v", tt.destination, got, tt.want)
+		}
+	}
+}
+
+func TestIsBlackhole(t *testing.T) {
+	loopbackAddresses := []netip.Prefix{
+		netip.MustParsePrefix("127.0.0.1/8"),
+		netip.MustParsePrefix("::1/128"),
+	}
+
+	tests := []struct {
+		destination string
+		loopback    bool
+		want        bool
+	}{
+		{destination: "203.0.113.0/24", loopback: true, want: true},
+		{destination: "198.51.100.7/32", loopback: true, want: true},
+		{destination: "2001:db8:bad::/48", loopback: true, want: true},
+		{destination: "127.0.0.0/8", loopback: true, want: false},
+		{destination: "127.255.255.255/32", loopback: true, want: false},
+		{destination: "::1/128", loopback: true, want: false},
+		{destination: "224.0.0.0/4", loopback: true, want: false},
+		{destination: "255.255.255.255/32", loopback: true, want: false},
+		{destination: "203.0.113.0/24", loopback: false, want: false},
+		{destination: "0.0.0.0/0", loopback: false, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			got := IsBlackhole(netip.MustParsePrefix(tt.destination), tt.loopback, loopbackAddresses)
+			if got != tt.want {
+				t.Fatalf("IsBlackhole(%s, %v): expected %v, got %v", tt.destination, tt.loopback, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIPv6Classes(t *testing.T) {
+	tests := []struct {
+		destination string
+		linkLocal   bool
+		uniqueLocal bool
+		multicast   bool
+	}{
+		{destination: "fe80::/10", linkLocal: true},
+		{destination: "fe80::/64", linkLocal: true},
+		{destination: "febf:ffff::/32", linkLocal: true},
+		{destination: "fe80::/9"},
+		{destination: "fec0::/10"},
+		{destination: "fe7f::/16"},
+		{destination: "fc00::/7", uniqueLocal: true},
+		{destination: "fd12:3456:789a::/48", uniqueLocal: true},
+		{destination: "fdff:ffff:ffff:ffff::/64", uniqueLocal: true},
+		{destination: "fc00::/6"},
+		{destination: "fbff::/16"},
+		{destination: "fe00::/16"},
+		{destination: "ff00::/8", multicast: true},
+		{destination: "ff02::1/128", multicast: true},
+		{destination: "ff00::/7"},
+		{destination: "feff::/16"},
+		{destination: "2001:db8::/32"},
+		{destination: "::/0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			prefix := netip.MustParsePrefix(tt.destination)
+			if got := IsLinkLocal(prefix); got != tt.linkLocal {
+				t.Fatalf("IsLinkLocal: expected %v, got %v", tt.linkLocal, got)
+			}
+			if got := IsUniqueLocal(prefix); got != tt.uniqueLocal {
+				t.Fatalf("IsUniqueLocal: expected %v, got %v", tt.uniqueLocal, got)
+			}
+			if got := IsMulticast(prefix); got != tt.multicast {
+				t.Fatalf("IsMulticast: expected %v, got %v", tt.multicast, got)
+			}
+		})
+	}
+}
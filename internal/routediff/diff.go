@@ -0,0 +1,51 @@
+package routediff
+
+// Diff compares two snapshots whose items are identified by key.
+//
+// added holds items of newItems whose key is absent from oldItems, removed holds
+// items of oldItems whose key is absent from newItems, and changed holds items of
+// newItems whose counterpart in oldItems differs according to differs. Results
+// keep the order of the snapshot they come from. When a key appears more than
+// once in a snapshot, only its first occurrence is considered.
+func Diff[T any, K comparable](
+	oldItems, newItems []T,
+	key func(T) K,
+	differs func(oldItem, newItem T) bool,
+) (added, removed, changed []T) {
+	oldByKey := make(map[K]T, len(oldItems))
+	for _, item := range oldItems {
+		k := key(item)
+		if _, exists := oldByKey[k]; !exists {
+			oldByKey[k] = item
+		}
+	}
+
+	seen := make(map[K]struct{}, len(newItems))
+	for _, item := range newItems {
+		k := key(item)
+		if _, dup := seen[k]; dup {
+			continue
+		}
+		seen[k] = struct{}{}
+
+		oldItem, exists := oldByKey[k]
+		switch {
+		case !exists:
+			added = append(added, item)
+		case differs(oldItem, item):
+			changed = append(changed, item)
+		}
+	}
+
+	for _, item := range oldItems {
+		k := key(item)
+		if _, exists := seen[k]; exists {
+			continue
+		}
+		// Mark as seen so duplicates in oldItems are reported once.
+		seen[k] = struct{}{}
+		removed = append(removed, item)
+	}
+
+	return added, removed, changed
+}
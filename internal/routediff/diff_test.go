@@ -0,0 +1,62 @@
+package routediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fakeRoute struct {
+	dest   string
+	metric int
+}
+
+func diffFakeRoutes(oldRoutes, newRoutes []fakeRoute) (added, removed, changed []fakeRoute) {
+	return Diff(
+		oldRoutes,
+		newRoutes,
+		func(r fakeRoute) string { return r.dest },
+		func(a, b fakeRoute) bool { return a.metric != b.metric },
+	)
+}
+
+func TestDiff(t *testing.T) {
+	oldRoutes := []fakeRoute{
+		{dest: "10.0.0.0/8", metric: 1},
+		{dest: "10.1.0.0/16", metric: 5},
+		{dest: "10.2.0.0/16", metric: 5},
+	}
+	newRoutes := []fakeRoute{
+		{dest: "10.1.0.0/16", metric: 10},
+		{dest: "10.2.0.0/16", metric: 5},
+		{dest: "10.3.0.0/16", metric: 1},
+	}
+
+	added, removed, changed := diffFakeRoutes(oldRoutes, newRoutes)
+	if want := []fakeRoute{{dest: "10.3.0.0/16", metric: 1}}; !reflect.DeepEqual(added, want) {
+		t.Fatalf("expected added %v, got %v", want, added)
+	}
+	if want := []fakeRoute{{dest: "10.0.0.0/8", metric: 1}}; !reflect.DeepEqual(removed, want) {
+		t.Fatalf("expected removed %v, got %v", want, removed)
+	}
+	if want := []fakeRoute{{dest: "10.1.0.0/16", metric: 10}}; !reflect.DeepEqual(changed, want) {
+		t.Fatalf("expected changed %v, got %v", want, changed)
+	}
+}
+
+func TestDiffIdenticalSnapshots(t *testing.T) {
+	routes := []fakeRoute{{dest: "10.0.0.0/8", metric: 1}}
+	added, removed, changed := diffFakeRoutes(routes, routes)
+	if added != nil || removed != nil || changed != nil {
+		t.Fatalf("expected no differences, got added=%v removed=%v changed=%v", added, removed, changed)
+	}
+}
+
+func TestDiffDuplicatesReportedOnce(t *testing.T) {
+	oldRoutes := []fakeRoute{{dest: "a"}, {dest: "a"}}
+	newRoutes := []fakeRoute{{dest: "b"}, {dest: "b"}}
+
+	added, removed, _ := diffFakeRoutes(oldRoutes, newRoutes)
+	if len(added) != 1 || len(removed) != 1 {
+		t.Fatalf("expected one added and one removed route, got added=%v removed=%v", added, removed)
+	}
+}
@@ -0,0 +1,57 @@
+// Package routefmt renders routes as compact one-line strings.
+package routefmt
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+)
+
+// protocolNames maps NL_ROUTE_PROTOCOL values to the names used by route.exe and
+// the MIB_IPPROTO_* constants.
+var protocolNames = map[uint32]string{
+	1:     "Other",
+	2:     "Local",
+	3:     "NetMgmt",
+	4:     "ICMP",
+	5:     "EGP",
+	6:     "GGP",
+	7:     "Hello",
+	8:     "RIP",
+	9:     "IS-IS",
+	10:    "ES-IS",
+	11:    "Cisco",
+	12:    "BBN",
+	13:    "OSPF",
+	14:    "BGP",
+	15:    "IDPR",
+	16:    "EIGRP",
+	17:    "DVMRP",
+	18:    "RPL",
+	19:    "DHCP",
+	10002: "NTAutostatic",
+	10006: "NTStatic",
+	10007: "NTStaticNonDOD",
+}
+
+// Protocol returns the name of a routing protocol, or its number when unknown.
+func Protocol(protocol uint32) string {
+	if name, ok := protocolNames[protocol]; ok {
+		return name
+	}
+	return strconv.FormatUint(uint64(protocol), 10)
+}
+
+// Route formats a route as
+//
+//	10.0.0.0/8 via 192.168.1.1 dev Ethernet(5) metric 10 [NetMgmt]
+//
+// On-link routes, whose next hop is unspecified, are written "on-link" instead of
+// "via <next hop>".
+func Route(destination netip.Prefix, nextHop netip.Addr, alias string, index uint32, metric uint32, protocol uint32) string {
+	via := "on-link"
+	if nextHop.IsValid() && !nextHop.IsUnspecified() {
+		via = "via " + nextHop.String()
+	}
+	return fmt.Sprintf("%s %s dev %s(%d) metric %d [%s]", destination, via, alias, index, metric, Protocol(protocol))
+}
@@ -0,0 +1,48 @@
+package routefmt
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestRoute(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		nextHop     string
+		alias       string
+		index       uint32
+		metric      uint32
+		protocol    uint32
+		want        string
+	}{
+		{
+			name:        "gateway route",
+			destination: "10.0.0.0/8", nextHop: "192.168.1.1", alias: "Ethernet", index: 5, metric: 10, protocol: 3,
+			want: "10.0.0.0/8 via 192.168.1.1 dev Ethernet(5) metric 10 [NetMgmt]",
+		},
+		{
+			name:        "on-link route",
+			destination: "192.168.1.0/24", nextHop: "0.0.0.0", alias: "以太网", index: 12, metric: 256, protocol: 2,
+			want: "192.168.1.0/24 on-link dev 以太网(12) metric 256 [Local]",
+		},
+		{
+			name:        "ipv6 link-local next hop",
+			destination: "::/0", nextHop: "fe80::1", alias: "Wi-Fi", index: 7, metric: 0, protocol: 19,
+			want: "::/0 via fe80::1 dev Wi-Fi(7) metric 0 [DHCP]",
+		},
+		{
+			name:        "unknown protocol",
+			destination: "::/0", nextHop: "::", alias: "Tunnel", index: 30, metric: 5, protocol: 77,
+			want: "::/0 on-link dev Tunnel(30) metric 5 [77]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Route(netip.MustParsePrefix(tt.destination), netip.MustParseAddr(tt.nextHop), tt.alias, tt.index, tt.metric, tt.protocol)
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Package routehash computes a stable, order-independent hash of a set of routes.
+package routehash
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"net/netip"
+	"slices"
+)
+
+// Route holds the fields that take part in the hash.
+type Route struct {
+	Destination    netip.Prefix
+	NextHop        netip.Addr
+	InterfaceIndex uint32
+	Metric         uint32
+}
+
+// Sum returns a 64-bit FNV-1a hash of routes. The result does not depend on
+// the order of routes or on IPv6 zones, and is the same across runs and
+// machines. Each route is hashed on its own; the sorted per-route hashes are
+// then hashed together, so duplicated routes change the result.
+func Sum(routes []Route) uint64 {
+	sums := make([]uint64, 0, len(routes))
+	for _, r := range routes {
+		sums = append(sums, sumRoute(r))
+	}
+	slices.Sort(sums)
+
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, sum := range sums {
+		binary.BigEndian.PutUint64(buf[:], sum)
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+func sumRoute(r Route) uint64 {
+	h := fnv.New64a()
+	h.Write(r.Destination.Addr().AsSlice())
+	h.Write([]byte{byte(r.Destination.Bits())})
+	// The next hop may be invalid; a length byte keeps it from blending into
+	// the interface index that follows.
+	nextHop := r.NextHop.WithZone("").AsSlice()
+	h.Write([]byte{byte(len(nextHop))})
+	h.Write(nextHop)
+	var buf [8]byte
+	binary.BigEndian.PutUint32(buf[:4], r.InterfaceIndex)
+	binary.BigEndian.PutUint32(buf[4:], r.Metric)
+	h.Write(buf[:])
+	return h.Sum64()
+}
@@ -2,7 +2,7 @@ package routeops
 
 import "fmt"
 
-// ErrorAction defines how batch deletion behaves after a route deletion error.
+// ErrorAction defines how batch operations behave after a route operation error.
 type ErrorAction int
 
 const (
@@ -10,20 +10,65 @@ const (
 	ErrorActionStop
 )
 
+// OpError records a failed operation on one route of a batch.
+type OpError struct {
+	Op    string // "add", "delete" or "update"
+	Route string // description of the route, as produced by the batch's describe function
+	Err   error
+}
+
+func (e *OpError) Error() string {
+	return fmt.Sprintf("failed to %s route (%s): %v", e.Op, e.Route, e.Err)
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
 // DeleteRoutes applies deleteFn to each route and either aggregates or stops on errors.
 func DeleteRoutes[T any](
 	routes []T,
 	deleteFn func(T) error,
 	describeFn func(T) string,
 	errorAction ErrorAction,
+) (partialErrs []error, err error) {
+	return apply(routes, deleteFn, describeFn, errorAction, "delete")
+}
+
+// AddRoutes applies addFn to each route and either aggregates or stops on errors.
+func AddRoutes[T any](
+	routes []T,
+	addFn func(T) error,
+	describeFn func(T) string,
+	errorAction ErrorAction,
+) (partialErrs []error, err error) {
+	return apply(routes, addFn, describeFn, errorAction, "add")
+}
+
+// UpdateRoutes applies updateFn to each route and either aggregates or stops on errors.
+func UpdateRoutes[T any](
+	routes []T,
+	updateFn func(T) error,
+	describeFn func(T) string,
+	errorAction ErrorAction,
+) (partialErrs []error, err error) {
+	return apply(routes, updateFn, describeFn, errorAction, "update")
+}
+
+func apply[T any](
+	routes []T,
+	opFn func(T) error,
+	describeFn func(T) string,
+	errorAction ErrorAction,
+	verb string,
 ) (partialErrs []error, err error) {
 	if len(routes) == 0 {
 		return nil, nil
 	}
 
 	for _, route := range routes {
-		if delErr := deleteFn(route); delErr != nil {
-			wrappedErr := fmt.Errorf("failed to delete route (%s): %w", describeFn(route), delErr)
+		if opErr := opFn(route); opErr != nil {
+			wrappedErr := &OpError{Op: verb, Route: describeFn(route), Err: opErr}
 			if errorAction == ErrorActionStop {
 				return nil, wrappedErr
 			}
@@ -68,3 +68,54 @@ func TestDeleteRoutesStopReturnsFirstError(t *testing.T) {
 		t.Fatalf("expected deletion to stop after second route, got %d attempts", len(deleted))
 	}
 }
+
+func TestAddRoutesDescribesOperation(t *testing.T) {
+	routes := []fakeRoute{{name: "bad-1", err: errors.New("boom-1")}}
+
+	partialErrs, err := AddRoutes(
+		routes,
+		func(route fakeRoute) error { return route.err },
+		func(route fakeRoute) string { return route.name },
+		ErrorActionContinue,
+	)
+	if err != nil {
+		t.Fatalf("expected nil fatal error, got %v", err)
+	}
+	if len(partialErrs) != 1 || !strings.Contains(partialErrs[0].Error(), "failed to add route (bad-1)") {
+		t.Fatalf("expected add error for bad-1, got %v", partialErrs)
+	}
+}
+
+func TestUpdateRoutesDescribesOperation(t *testing.T) {
+	routes := []fakeRoute{{name: "ok"}, {name: "bad-1", err: errors.New("boom-1")}}
+
+	partialErrs, err := UpdateRoutes(
+		routes,
+		func(route fakeRoute) error { return route.err },
+		func(route fakeRoute) string { return route.name },
+		ErrorActionContinue,
+	)
+	if err != nil {
+		t.Fatalf("expected nil fatal error, got %v", err)
+	}
+	if len(partialErrs) != 1 || !strings.Contains(partialErrs[0].Error(), "failed to update route (bad-1)") {
+		t.Fatalf("expected update error for bad-1, got %v", partialErrs)
+	}
+}
+
+func TestOpErrorUnwraps(t *testing.T) {
+	boom := errors.New("boom-1")
+	_, err := DeleteRoutes(
+		[]fakeRoute{{name: "bad-1", err: boom}},
+		func(route fakeRoute) error { return route.err },
+		func(route fakeRoute) string { return route.name },
+		ErrorActionStop,
+	)
+	var opErr *OpError
+	if !errors.As(err, &opErr) {
+		t.Fatalf("expected an *OpError, got %T", err)
+	}
+	if opErr.Op != "delete" || opErr.Route != "bad-1" || !errors.Is(err, boom) {
+		t.Fatalf("unexpected error fields: %+v", opErr)
+	}
+}
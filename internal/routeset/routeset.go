@@ -0,0 +1,61 @@
+// Package routeset implements set operations on slices whose elements are
+// identified by a comparable key.
+package routeset
+
+// Union returns the elements of a followed by the elements of b whose key is
+// not in a. Each key appears once, as its first occurrence.
+func Union[T any, K comparable](a, b []T, key func(T) K) []T {
+	seen := make(map[K]struct{}, len(a)+len(b))
+	var result []T
+	for _, items := range [][]T{a, b} {
+		for _, item := range items {
+			if add(seen, key(item)) {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+// Intersection returns the elements of a whose key is also in b, in the order
+// of a. Each key appears once.
+func Intersection[T any, K comparable](a, b []T, key func(T) K) []T {
+	return filter(a, keys(b, key), key, true)
+}
+
+// Difference returns the elements of a whose key is not in b, in the order of
+// a. Each key appears once.
+func Difference[T any, K comparable](a, b []T, key func(T) K) []T {
+	return filter(a, keys(b, key), key, false)
+}
+
+// filter keeps the elements of items whose presence in set equals want,
+// dropping repeated keys.
+func filter[T any, K comparable](items []T, set map[K]struct{}, key func(T) K, want bool) []T {
+	seen := make(map[K]struct{}, len(items))
+	var result []T
+	for _, item := range items {
+		k := key(item)
+		if _, ok := set[k]; ok == want && add(seen, k) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func keys[T any, K comparable](items []T, key func(T) K) map[K]struct{} {
+	set := make(map[K]struct{}, len(items))
+	for _, item := range items {
+		set[key(item)] = struct{}{}
+	}
+	return set
+}
+
+// add inserts k into seen and reports whether it was not already present.
+func add[K comparable](seen map[K]struct{}, k K) bool {
+	if _, ok := seen[k]; ok {
+		return false
+	}
+	seen[k] = struct{}{}
+	return true
+}
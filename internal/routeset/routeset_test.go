@@ -0,0 +1,38 @@
+package routeset
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// key identifies an item by its name before the slash, so "a/1" and "a/2" are equal.
+func key(item string) string {
+	name, _, _ := strings.Cut(item, "/")
+	return name
+}
+
+func TestSetOperations(t *testing.T) {
+	a := []string{"a/1", "b/1", "a/2", "c/1"}
+	b := []string{"c/2", "d/1", "b/2", "d/2"}
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{name: "union", got: Union(a, b, key), want: []string{"a/1", "b/1", "c/1", "d/1"}},
+		{name: "intersection", got: Intersection(a, b, key), want: []string{"b/1", "c/1"}},
+		{name: "difference", got: Difference(a, b, key), want: []string{"a/1"}},
+		{name: "reverse difference", got: Difference(b, a, key), want: []string{"d/1"}},
+		{name: "empty intersection", got: Intersection(a, nil, key)},
+		{name: "difference with nothing", got: Difference(a, nil, key), want: []string{"a/1", "b/1", "c/1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !slices.Equal(tt.got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, tt.got)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package scope
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+)
+
+// ResolveNextHop maps the IPv6 zone of nextHop onto an interface index and checks
+// it against ifaceIndex.
+//
+// A numeric zone (fe80::1%5) is treated as an interface index. Any other zone is
+// passed to lookupAlias, which resolves an interface alias to its index. The
+// returned address carries the numeric zone so it can be converted to a
+// scope ID. Addresses without a zone are returned unchanged.
+func ResolveNextHop(
+	nextHop netip.Addr,
+	ifaceIndex uint32,
+	lookupAlias func(alias string) (uint32, error),
+) (netip.Addr, error) {
+	zone := nextHop.Zone()
+	if zone == "" {
+		return nextHop, nil
+	}
+
+	zoneIndex, err := parseZone(zone, lookupAlias)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid zone in next hop %s: %w", nextHop, err)
+	}
+	if zoneIndex != ifaceIndex {
+		return netip.Addr{}, fmt.Errorf(
+			"next hop %s is scoped to interface %d, which conflicts with interface index %d",
+			nextHop,
+			zoneIndex,
+			ifaceIndex,
+		)
+	}
+
+	return nextHop.WithZone(strconv.FormatUint(uint64(zoneIndex), 10)), nil
+}
+
+func parseZone(zone string, lookupAlias func(string) (uint32, error)) (uint32, error) {
+	if index, err := strconv.ParseUint(zone, 10, 32); err == nil {
+		return uint32(index), nil
+	}
+	if lookupAlias == nil {
+		return 0, fmt.Errorf("zone %q is not an interface index", zone)
+	}
+	return lookupAlias(zone)
+}
@@ -0,0 +1,62 @@
+package scope
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestResolveNextHopWithoutZone(t *testing.T) {
+	for _, s := range []string{"192.168.1.1", "fe80::1", "2001:db8::1"} {
+		addr := netip.MustParseAddr(s)
+		got, err := ResolveNextHop(addr, 5, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", s, err)
+		}
+		if got != addr {
+			t.Fatalf("%s: expected address to be unchanged, got %s", s, got)
+		}
+	}
+}
+
+func TestResolveNextHopLinkLocalNumericZone(t *testing.T) {
+	got, err := ResolveNextHop(netip.MustParseAddr("fe80::1%5"), 5, nil)
+	if err != nil {
+		t.Fatalf("expected matching zone to pass, got %v", err)
+	}
+	if got.Zone() != "5" || got.WithZone("") != netip.MustParseAddr("fe80::1") {
+		t.Fatalf("expected fe80::1%%5, got %s", got)
+	}
+}
+
+func TestResolveNextHopLinkLocalAliasZone(t *testing.T) {
+	lookup := func(alias string) (uint32, error) {
+		if alias == "以太网" {
+			return 12, nil
+		}
+		return 0, errors.New("no such interface")
+	}
+
+	got, err := ResolveNextHop(netip.MustParseAddr("fe80::1%以太网"), 12, lookup)
+	if err != nil {
+		t.Fatalf("expected alias zone to resolve, got %v", err)
+	}
+	if got.Zone() != "12" {
+		t.Fatalf("expected alias zone to be rewritten to index, got %q", got.Zone())
+	}
+
+	if _, err := ResolveNextHop(netip.MustParseAddr("fe80::1%Wi-Fi"), 12, lookup); err == nil {
+		t.Fatal("expected unknown alias zone to fail")
+	}
+}
+
+func TestResolveNextHopZoneConflict(t *testing.T) {
+	_, err := ResolveNextHop(netip.MustParseAddr("fe80::1%7"), 5, nil)
+	if err == nil {
+		t.Fatal("expected conflicting zone to fail")
+	}
+	if !strings.Contains(err.Error(), "conflicts with interface index 5") {
+		t.Fatalf("expected descriptive conflict error, got %q", err)
+	}
+}
@@ -0,0 +1,40 @@
+package srcaddr
+
+import "net/netip"
+
+// Select picks the local address an interface would most likely use as the source
+// for traffic sent along a route to destination via nextHop.
+//
+// addresses are the interface's unicast addresses, each carrying its on-link
+// prefix length. Only addresses of the destination's family are considered. An
+// address whose on-link prefix contains the next hop (or, for on-link routes, the
+// destination itself) is preferred. Otherwise the first address of the family is
+// used, skipping IPv6 link-local addresses unless the destination is link-local.
+// The zero Addr is returned when the interface has no usable address.
+func Select(addresses []netip.Prefix, destination netip.Prefix, nextHop netip.Addr) netip.Addr {
+	dest := destination.Addr()
+	target := nextHop.WithZone("")
+	if !target.IsValid() || target.IsUnspecified() {
+		target = dest
+	}
+
+	var fallback netip.Addr
+	for _, address := range addresses {
+		addr := address.Addr()
+		if addr.Is4() != dest.Is4() {
+			continue
+		}
+		if address.Masked().Contains(target) {
+			return addr
+		}
+		if fallback.IsValid() {
+			continue
+		}
+		if addr.Is6() && addr.IsLinkLocalUnicast() && !dest.IsLinkLocalUnicast() {
+			continue
+		}
+		fallback = addr
+	}
+
+	return fallback
+}
@@ -0,0 +1,46 @@
+package srcaddr
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	addresses := []netip.Prefix{
+		netip.MustParsePrefix("fe80::1234/64"),
+		netip.MustParsePrefix("192.168.1.10/24"),
+		netip.MustParsePrefix("10.0.0.5/8"),
+		netip.MustParsePrefix("2001:db8::10/64"),
+	}
+
+	tests := []struct {
+		name        string
+		destination string
+		nextHop     string
+		want        string
+	}{
+		{name: "next hop on second subnet", destination: "0.0.0.0/0", nextHop: "10.0.0.1", want: "10.0.0.5"},
+		{name: "next hop on first subnet", destination: "172.16.0.0/12", nextHop: "192.168.1.1", want: "192.168.1.10"},
+		{name: "on-link destination", destination: "10.1.0.0/16", nextHop: "0.0.0.0", want: "10.0.0.5"},
+		{name: "unreachable next hop falls back", destination: "0.0.0.0/0", nextHop: "172.16.0.1", want: "192.168.1.10"},
+		{name: "v6 skips link-local", destination: "::/0", nextHop: "2001:db8::1", want: "2001:db8::10"},
+		{name: "v6 link-local next hop", destination: "::/0", nextHop: "fe80::1%5", want: "fe80::1234"},
+		{name: "v6 link-local destination", destination: "fe80::/64", nextHop: "::", want: "fe80::1234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Select(addresses, netip.MustParsePrefix(tt.destination), netip.MustParseAddr(tt.nextHop))
+			if want := netip.MustParseAddr(tt.want); got != want {
+				t.Fatalf("expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestSelectNoAddressOfFamily(t *testing.T) {
+	addresses := []netip.Prefix{netip.MustParsePrefix("192.168.1.10/24")}
+	got := Select(addresses, netip.MustParsePrefix("::/0"), netip.MustParseAddr("2001:db8::1"))
+	if got.IsValid() {
+		t.Fatalf("expected no source address, got %s", got)
+	}
+}
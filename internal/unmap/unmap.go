@@ -0,0 +1,16 @@
+// Package unmap converts IPv4-mapped IPv6 prefixes to their plain IPv4 form.
+package unmap
+
+import "net/netip"
+
+// Prefix returns p with an IPv4-mapped IPv6 address (::ffff:a.b.c.d) converted
+// to IPv4 and the prefix length reduced by 96, so ::ffff:10.0.0.0/104 becomes
+// 10.0.0.0/8. Prefixes shorter than /96 also cover non-mapped addresses and
+// have no IPv4 equivalent; they are returned unchanged, as are all other
+// prefixes.
+func Prefix(p netip.Prefix) netip.Prefix {
+	if !p.Addr().Is4In6() || p.Bits() < 96 {
+		return p
+	}
+	return netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+}
@@ -0,0 +1,27 @@
+package unmap
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestPrefix(t *testing.T) {
+	tests := map[string]string{
+		"::ffff:10.0.0.0/104":    "10.0.0.0/8",
+		"::ffff:192.168.1.5/128": "192.168.1.5/32",
+		"::ffff:0.0.0.0/96":      "0.0.0.0/0",
+		"::ffff:10.0.0.0/64":     "::ffff:10.0.0.0/64",
+		"10.0.0.0/8":             "10.0.0.0/8",
+		"2001:db8::/32":          "2001:db8::/32",
+		"::ffff:10.0.0.5/104":    "10.0.0.5/8",
+	}
+	for in, want := range tests {
+		if got := Prefix(netip.MustParsePrefix(in)); got.String() != want {
+			t.Errorf("Prefix(%s) = %s, want %s", in, got, want)
+		}
+	}
+
+	if got := Prefix(netip.Prefix{}); got.IsValid() {
+		t.Errorf("Prefix of the zero prefix = %s, want the zero prefix", got)
+	}
+}
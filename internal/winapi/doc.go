@@ -0,0 +1,9 @@
+// Package winapi provides the winipcfg and x/sys/windows types and constants
+// used by the root package. On Windows they are aliases of the real ones, so
+// the root package's API is unchanged. On other platforms they are portable
+// stand-ins with the same names, fields and methods. This lets the
+// platform-independent logic and its tests build and run on any OS.
+//
+// Only what the root package uses is declared here. The stand-ins never call
+// into the system.
+package winapi
@@ -0,0 +1,188 @@
+//go:build !windows
+
+package winapi
+
+import (
+	"net/netip"
+	"strconv"
+)
+
+// LUID is the locally unique identifier of a network interface. The interface
+// type (IfType) is encoded in its top 16 bits.
+type LUID uint64
+
+// AddressFamily is an AF_* address family.
+type AddressFamily uint16
+
+// IfType is an IANA ifType, for example IfTypeEthernetCSMACD.
+type IfType uint32
+
+// IfOperStatus is the operational status of an interface (RFC 2863).
+type IfOperStatus uint32
+
+// RouteProtocol is the protocol that installed a route (RFC 4292).
+type RouteProtocol uint32
+
+// RouteOrigin is the origin of a route.
+type RouteOrigin uint32
+
+// MibNotificationType is the kind of change reported to a change callback.
+type MibNotificationType uint32
+
+const (
+	AF_UNSPEC = 0
+	AF_INET   = 2
+	AF_INET6  = 23
+)
+
+const (
+	IfTypeEthernetCSMACD   IfType = 6
+	IfTypeSoftwareLoopback IfType = 24
+	IfTypeIEEE80211        IfType = 71
+
+	IfOperStatusUp   IfOperStatus = 1
+	IfOperStatusDown IfOperStatus = 2
+
+	RouteProtocolNetMgmt RouteProtocol = 3
+	RouteOriginManual    RouteOrigin   = 0
+
+	MibParameterNotification MibNotificationType = 0
+	MibAddInstance           MibNotificationType = 1
+	MibDeleteInstance        MibNotificationType = 2
+)
+
+// Errno is a Windows system error code.
+type Errno uintptr
+
+func (e Errno) Error() string {
+	return "windows error " + strconv.FormatUint(uint64(e), 10)
+}
+
+const (
+	ERROR_FILE_NOT_FOUND        Errno = 2
+	ERROR_ACCESS_DENIED         Errno = 5
+	ERROR_NOT_READY             Errno = 21
+	ERROR_NETWORK_BUSY          Errno = 54
+	ERROR_INVALID_PARAMETER     Errno = 87
+	ERROR_BUSY                  Errno = 170
+	ERROR_NOT_FOUND             Errno = 1168
+	ERROR_NETWORK_UNREACHABLE   Errno = 1231
+	ERROR_RETRY                 Errno = 1237
+	ERROR_TIMEOUT               Errno = 1460
+	ERROR_DEVICE_NOT_AVAILABLE  Errno = 4319
+	ERROR_OBJECT_ALREADY_EXISTS Errno = 5010
+)
+
+// RawSockaddrInet holds an IPv4 or IPv6 socket address. Like the winipcfg
+// type, an IPv6 zone is kept only when it is a numeric scope ID.
+type RawSockaddrInet struct {
+	Family  AddressFamily
+	addr    [16]byte
+	scopeID uint32
+}
+
+// SetAddr sets the family and address. It fails with ERROR_INVALID_PARAMETER
+// for an invalid address.
+func (a *RawSockaddrInet) SetAddr(addr netip.Addr) error {
+	*a = RawSockaddrInet{}
+	switch {
+	case addr.Is4():
+		a.Family = AF_INET
+		a4 := addr.As4()
+		copy(a.addr[:], a4[:])
+	case addr.Is6():
+		a.Family = AF_INET6
+		a.addr = addr.As16()
+		if scopeID, err := strconv.ParseUint(addr.Zone(), 10, 32); err == nil {
+			a.scopeID = uint32(scopeID)
+		}
+	default:
+		return ERROR_INVALID_PARAMETER
+	}
+	return nil
+}
+
+// Addr returns the address, or the zero Addr if the family is neither
+// AF_INET nor AF_INET6.
+func (a *RawSockaddrInet) Addr() netip.Addr {
+	switch a.Family {
+	case AF_INET:
+		return netip.AddrFrom4([4]byte(a.addr[:4]))
+	case AF_INET6:
+		addr := netip.AddrFrom16(a.addr)
+		if a.scopeID != 0 {
+			addr = addr.WithZone(strconv.FormatUint(uint64(a.scopeID), 10))
+		}
+		return addr
+	}
+	return netip.Addr{}
+}
+
+// IPAddressPrefix is an IP address prefix.
+type IPAddressPrefix struct {
+	RawPrefix    RawSockaddrInet
+	PrefixLength uint8
+}
+
+// SetPrefix sets the prefix.
+func (p *IPAddressPrefix) SetPrefix(prefix netip.Prefix) error {
+	if err := p.RawPrefix.SetAddr(prefix.Addr()); err != nil {
+		return err
+	}
+	p.PrefixLength = uint8(prefix.Bits())
+	return nil
+}
+
+// Prefix returns the prefix. The zone of the address is dropped.
+func (p *IPAddressPrefix) Prefix() netip.Prefix {
+	switch p.RawPrefix.Family {
+	case AF_INET, AF_INET6:
+		return netip.PrefixFrom(p.RawPrefix.Addr().WithZone(""), int(p.PrefixLength))
+	}
+	return netip.Prefix{}
+}
+
+// MibIPforwardRow2 is an IP route entry (MIB_IPFORWARD_ROW2).
+type MibIPforwardRow2 struct {
+	InterfaceLUID        LUID
+	InterfaceIndex       uint32
+	DestinationPrefix    IPAddressPrefix
+	NextHop              RawSockaddrInet
+	SitePrefixLength     uint8
+	ValidLifetime        uint32
+	PreferredLifetime    uint32
+	Metric               uint32
+	Protocol             RouteProtocol
+	Loopback             bool
+	AutoconfigureAddress bool
+	Publish              bool
+	Immortal             bool
+	Age                  uint32
+	Origin               RouteOrigin
+}
+
+// Init sets the defaults that InitializeIpForwardEntry sets on Windows:
+// infinite lifetimes, a manually added NetMgmt route, and the Loopback,
+// AutoconfigureAddress and Immortal flags.
+func (row *MibIPforwardRow2) Init() {
+	*row = MibIPforwardRow2{
+		ValidLifetime:        0xffffffff,
+		PreferredLifetime:    0xffffffff,
+		Protocol:             RouteProtocolNetMgmt,
+		Loopback:             true,
+		AutoconfigureAddress: true,
+		Immortal:             true,
+		Origin:               RouteOriginManual,
+	}
+}
+
+// MibIPInterfaceRow is the per-family IP information of an interface
+// (MIB_IPINTERFACE_ROW). Only the fields used by the root package are declared.
+type MibIPInterfaceRow struct {
+	Family             AddressFamily
+	InterfaceLUID      LUID
+	InterfaceIndex     uint32
+	UseAutomaticMetric bool
+	Metric             uint32
+	Connected          bool
+}
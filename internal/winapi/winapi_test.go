@@ -0,0 +1,54 @@
+package winapi
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestRawSockaddrInet(t *testing.T) {
+	tests := map[string]string{
+		"192.168.1.1": "192.168.1.1",
+		"2001:db8::1": "2001:db8::1",
+		"fe80::1%5":   "fe80::1%5",
+		"fe80::1%eth": "fe80::1",
+	}
+	for in, want := range tests {
+		var a RawSockaddrInet
+		if err := a.SetAddr(netip.MustParseAddr(in)); err != nil {
+			t.Errorf("SetAddr(%s): %v", in, err)
+			continue
+		}
+		if got := a.Addr(); got.String() != want {
+			t.Errorf("SetAddr(%s): Addr() = %s, want %s", in, got, want)
+		}
+	}
+
+	var a RawSockaddrInet
+	if err := a.SetAddr(netip.Addr{}); err == nil {
+		t.Error("SetAddr(invalid): expected an error")
+	}
+}
+
+func TestIPAddressPrefix(t *testing.T) {
+	for _, in := range []string{"10.0.0.0/8", "0.0.0.0/0", "2001:db8::/32", "fe80::/64"} {
+		var p IPAddressPrefix
+		if err := p.SetPrefix(netip.MustParsePrefix(in)); err != nil {
+			t.Errorf("SetPrefix(%s): %v", in, err)
+			continue
+		}
+		if got := p.Prefix(); got.String() != in {
+			t.Errorf("SetPrefix(%s): Prefix() = %s", in, got)
+		}
+	}
+}
+
+func TestMibIPforwardRow2Family(t *testing.T) {
+	var row MibIPforwardRow2
+	row.Init()
+	if err := row.DestinationPrefix.SetPrefix(netip.MustParsePrefix("2001:db8::/32")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if row.DestinationPrefix.RawPrefix.Family != AF_INET6 {
+		t.Errorf("expected AF_INET6, got %d", row.DestinationPrefix.RawPrefix.Family)
+	}
+}
@@ -0,0 +1,60 @@
+//go:build windows
+
+package winapi
+
+import (
+	"golang.org/x/sys/windows"
+	"golang.zx2c4.com/wireguard/windows/tunnel/winipcfg"
+)
+
+type (
+	LUID                = winipcfg.LUID
+	AddressFamily       = winipcfg.AddressFamily
+	IfType              = winipcfg.IfType
+	IfOperStatus        = winipcfg.IfOperStatus
+	RouteProtocol       = winipcfg.RouteProtocol
+	RouteOrigin         = winipcfg.RouteOrigin
+	MibNotificationType = winipcfg.MibNotificationType
+	RawSockaddrInet     = winipcfg.RawSockaddrInet
+	IPAddressPrefix     = winipcfg.IPAddressPrefix
+	MibIPforwardRow2    = winipcfg.MibIPforwardRow2
+	MibIPInterfaceRow   = winipcfg.MibIPInterfaceRow
+	Errno               = windows.Errno
+)
+
+const (
+	AF_UNSPEC = windows.AF_UNSPEC
+	AF_INET   = windows.AF_INET
+	AF_INET6  = windows.AF_INET6
+)
+
+const (
+	IfTypeEthernetCSMACD   = winipcfg.IfTypeEthernetCSMACD
+	IfTypeSoftwareLoopback = winipcfg.IfTypeSoftwareLoopback
+	IfTypeIEEE80211        = winipcfg.IfTypeIEEE80211
+
+	IfOperStatusUp   = winipcfg.IfOperStatusUp
+	IfOperStatusDown = winipcfg.IfOperStatusDown
+
+	RouteProtocolNetMgmt = winipcfg.RouteProtocolNetMgmt
+	RouteOriginManual    = winipcfg.RouteOriginManual
+
+	MibParameterNotification = winipcfg.MibParameterNotification
+	MibAddInstance           = winipcfg.MibAddInstance
+	MibDeleteInstance        = winipcfg.MibDeleteInstance
+)
+
+const (
+	ERROR_FILE_NOT_FOUND        = windows.ERROR_FILE_NOT_FOUND
+	ERROR_ACCESS_DENIED         = windows.ERROR_ACCESS_DENIED
+	ERROR_NOT_READY             = windows.ERROR_NOT_READY
+	ERROR_NETWORK_BUSY          = windows.ERROR_NETWORK_BUSY
+	ERROR_INVALID_PARAMETER     = windows.ERROR_INVALID_PARAMETER
+	ERROR_BUSY                  = windows.ERROR_BUSY
+	ERROR_NOT_FOUND             = windows.ERROR_NOT_FOUND
+	ERROR_NETWORK_UNREACHABLE   = windows.ERROR_NETWORK_UNREACHABLE
+	ERROR_RETRY                 = windows.ERROR_RETRY
+	ERROR_TIMEOUT               = windows.ERROR_TIMEOUT
+	ERROR_DEVICE_NOT_AVAILABLE  = windows.ERROR_DEVICE_NOT_AVAILABLE
+	ERROR_OBJECT_ALREADY_EXISTS = windows.ERROR_OBJECT_ALREADY_EXISTS
+)
@@ -0,0 +1,35 @@
+package winroute
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// logger 是包内使用的日志记录器，默认丢弃所有日志。
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	SetLogger(nil)
+}
+
+// SetLogger 设置包内使用的日志记录器。
+// 包会在每次系统调用（构建接口缓存、获取路由表、增删路由等）完成后输出 Debug 级别的日志，
+// 系统调用失败时日志中包含 "err" 属性。传入 nil 恢复默认的空日志记录器，即不输出任何日志。
+// SetLogger 可以被并发调用。
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = slog.New(slog.DiscardHandler)
+	}
+	logger.Store(l)
+}
+
+// logSyscall 记录一次系统调用的结果。
+func logSyscall(call string, err error, attrs ...any) {
+	l := logger.Load()
+	if err != nil {
+		attrs = append(attrs, slog.Any("err", err))
+		l.Debug("winroute: "+call+" failed", attrs...)
+		return
+	}
+	l.Debug("winroute: "+call, attrs...)
+}
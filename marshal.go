@@ -0,0 +1,43 @@
+package winroute
+
+import "encoding/json"
+
+// routeJSON 是 Route 的规范 JSON 表示，字段名与 wroute get -o json 的输出一致。
+type routeJSON struct {
+	Destination          string `json:"destination"`
+	NextHop              string `json:"next_hop"`
+	Metric               uint32 `json:"metric"`
+	InterfaceIndex       uint32 `json:"interface_index"`
+	InterfaceAlias       string `json:"interface_alias"`
+	InterfaceDescription string `json:"interface_description"`
+	Protocol             uint32 `json:"protocol"`
+	Origin               uint32 `json:"origin"`
+}
+
+func newRouteJSON(route *Route) routeJSON {
+	return routeJSON{
+		Destination:          route.Destination.String(),
+		NextHop:              route.NextHop.String(),
+		Metric:               route.Metric,
+		InterfaceIndex:       route.Interface.Index,
+		InterfaceAlias:       route.Interface.Alias,
+		InterfaceDescription: route.Interface.Description,
+		Protocol:             uint32(route.Protocol),
+		Origin:               uint32(route.Origin),
+	}
+}
+
+// MarshalJSON 把路由编码为规范的 JSON 对象，即 MarshalRoutes 输出的数组元素。
+func (r *Route) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newRouteJSON(r))
+}
+
+// MarshalRoutes 将路由列表编码为规范的 JSON 数组（与 wroute get -o json 的格式相同），
+// 便于嵌入 winroute 的服务程序直接通过 HTTP 等方式提供路由数据。
+// routes 为空时返回 []，而不是 null。
+func MarshalRoutes(routes []*Route) ([]byte, error) {
+	if routes == nil {
+		routes = []*Route{}
+	}
+	return json.Marshal(routes)
+}
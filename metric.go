@@ -0,0 +1,147 @@
+package winroute
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+
+	"github.com/bnkrr/winroute/internal/routeops"
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// ---- 接口 metric ----
+
+// 路由的实际优先级是路由 metric 与接口 metric 之和，
+// 因此一条 metric 很小的路由仍可能因为接口 metric 较大而不被优先选择。
+
+// ipInterfaceRows 返回接口在 IPv4 和 IPv6 上的 IP 接口行，未启用的地址族会被跳过。
+// 两个地址族都不存在时返回 ErrNotFound。
+func ipInterfaceRows(ifaceIndex uint32) ([]*winapi.MibIPInterfaceRow, error) {
+	luid, err := luidFromIndex(ifaceIndex, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*winapi.MibIPInterfaceRow
+	for _, family := range []winapi.AddressFamily{winapi.AF_INET, winapi.AF_INET6} {
+		row, err := provider.ipInterface(luid, family)
+		logSyscall("GetIpInterfaceEntry", err, "index", ifaceIndex, "family", family)
+		if errors.Is(err, winapi.ERROR_NOT_FOUND) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get IP interface: %w", err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("IP interface with index %d not found: %w", ifaceIndex, ErrNotFound)
+	}
+	return rows, nil
+}
+
+// GetInterfaceMetric 返回接口的 metric，以及该 metric 是否由系统自动计算。
+// 接口同时启用 IPv4 和 IPv6 时返回 IPv4 的设置（SetInterfaceMetric 会同时设置两者）。
+func GetInterfaceMetric(ifaceIndex uint32) (metric uint32, automatic bool, err error) {
+	rows, err := ipInterfaceRows(ifaceIndex)
+	if err != nil {
+		return 0, false, err
+	}
+	return rows[0].Metric, rows[0].UseAutomaticMetric, nil
+}
+
+// RecommendedMetric 返回在接口 ifaceIndex 上手动添加路由时建议使用的路由 metric：接口自身的 metric。
+//
+// 路由的实际优先级是路由 metric 与接口 metric 之和。系统通过 DHCP 等方式安装的路由通常 metric 为 0，
+// 使用建议值的路由会排在这些路由之后，同时仍随链路速度（自动接口 metric）与其他接口上的路由保持相对顺序。
+// metric 0 表示路由不设置自己的偏移量，实际 metric 就等于接口 metric（见 RouteSpec.Metric）。
+func RecommendedMetric(ifaceIndex uint32) (uint32, error) {
+	metric, _, err := GetInterfaceMetric(ifaceIndex)
+	if err != nil {
+		return 0, err
+	}
+	return max(metric, 1), nil
+}
+
+// SetInterfaceMetric 在接口已启用的所有地址族上设置固定的接口 metric，并关闭自动 metric。
+// metric 为 0 时恢复为系统自动计算的 metric。
+func SetInterfaceMetric(ifaceIndex uint32, metric uint32) error {
+	rows, err := ipInterfaceRows(ifaceIndex)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		row.UseAutomaticMetric = metric == 0
+		row.Metric = metric
+		err := provider.setIPInterface(row)
+		logSyscall("SetIpInterfaceEntry", err, "index", ifaceIndex, "family", row.Family, "metric", metric)
+		if err != nil {
+			return fmt.Errorf("failed to set interface metric: %w", mapAccessDenied(err))
+		}
+	}
+	return nil
+}
+
+// AdjustRouteMetric 将一条精确匹配的路由的 metric 增加 delta（delta 为负数时减少），
+// 无需事先知道其当前值，适合故障切换时升降路由优先级。结果小于 0 时取 0，超出 uint32 范围时取最大值。
+// 路由原地更新，不会被删除重建。路由不存在时返回 ErrNotFound。
+// destination 和 nextHop 的处理方式与 DeleteRoute 相同。
+func AdjustRouteMetric(destination netip.Prefix, nextHop netip.Addr, ifaceIndex uint32, delta int32) error {
+	destination, _ = normalizeDestination(destination)
+	nextHop, err := resolveNextHopZone(onLinkNextHop(destination, nextHop), ifaceIndex, nil)
+	if err != nil {
+		return err
+	}
+	luid, err := luidFromIndex(ifaceIndex, nil)
+	if err != nil {
+		return err
+	}
+	row, err := getRouteRow(luid, destination, nextHop, ifaceIndex)
+	if err != nil {
+		return err
+	}
+
+	row.Metric = uint32(min(max(int64(row.Metric)+int64(delta), 0), math.MaxUint32))
+	return setRouteRow(row, ifaceIndex)
+}
+
+// SetMetricForRoutes 将所有匹配过滤器的路由的 metric 原地设置为 metric，例如在迁移时降低某个接口上所有路由的优先级。
+// 没有提供过滤器时作用于所有路由，请谨慎使用。
+//
+// 返回值:
+//   - changed: 实际被修改的路由数量。metric 已经等于目标值的路由不会被修改，也不计入其中。
+//   - partialErrs: 每条更新失败的路由对应一个错误；其余路由仍会继续更新。
+//   - err: 致命错误（如无法获取路由列表），此时不会修改任何路由。
+func SetMetricForRoutes(metric uint32, filters ...FilterOption) (changed int, partialErrs []error, err error) {
+	if err := requireActiveStore(filters); err != nil {
+		return 0, nil, err
+	}
+	routes, err := GetRoutes(append(filters, IncludeRawRow(), Deduplicate())...)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to find routes for update: %w", err)
+	}
+
+	targets := routes[:0]
+	for _, route := range routes {
+		if route.Metric != metric {
+			targets = append(targets, route)
+		}
+	}
+
+	partialErrs, err = routeops.UpdateRoutes(
+		targets,
+		func(route *Route) error {
+			row := route.Raw()
+			row.Metric = metric
+			return setRouteRow(row, route.Interface.Index)
+		},
+		(*Route).String,
+		routeops.ErrorActionContinue,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(targets) - len(partialErrs), partialErrs, nil
+}
@@ -0,0 +1,25 @@
+package winroute
+
+import (
+	"net/netip"
+
+	"github.com/bnkrr/winroute/internal/netmask"
+)
+
+// PrefixFromNetmask 将 route.exe 风格的 "网络地址 + 点分掩码"（如 10.0.0.0 mask 255.0.0.0）
+// 转换为 CIDR 前缀。掩码必须连续且与网络地址属于同一地址族；
+// 网络地址在掩码之外设置了主机位时返回错误。
+func PrefixFromNetmask(network netip.Addr, mask netip.Addr) (netip.Prefix, error) {
+	return netmask.ToPrefix(network, mask)
+}
+
+// NetmaskString 返回前缀对应的点分掩码，例如 10.0.0.0/20 返回 "255.255.240.0"，
+// 与 route.exe 的 mask 参数格式相同。IPv6 前缀返回 IPv6 形式的掩码（如 "ffff:ffff::"），
+// 无效前缀返回空字符串。
+func NetmaskString(prefix netip.Prefix) string {
+	mask := netmask.Mask(prefix)
+	if !mask.IsValid() {
+		return ""
+	}
+	return mask.String()
+}
@@ -0,0 +1,78 @@
+package winroute
+
+// ---- PlanRoutes: 计算使路由表达到期望状态所需的变更 ----
+
+// PlanRoutes 比较当前路由表与期望的路由集合 desired，返回使路由表与 desired 一致所需的变更，
+// 但不执行任何修改（类似 terraform plan）：
+//   - toAdd: desired 中在路由表里不存在的路由；
+//   - toUpdate: 目标、下一跳和接口都已存在但 metric 不同的路由，值为 desired 中的期望状态；
+//   - toDelete: 路由表中由本包管理、却不在 desired 中的路由。
+//
+// 只有手动添加的静态路由（与 ExportRoutes 导出的路由相同）、且位于 desired 涉及的接口上，
+// 才会被计划删除；系统路由以及 DHCP 等自动生成的路由永远不会出现在 toDelete 中。
+// scope 可以进一步限制参与比较的现有路由，例如传入 WithInterfaceAliasPattern("vEthernet *")。
+// 被 scope 排除的现有路由视为不存在。scope 只能选择当前路由表，传入 WithStore(StorePersistent) 时返回错误。
+//
+// desired 中的目标前缀会被规范化（清除主机位），重复的路由只计算一次。
+func PlanRoutes(desired []RouteSpec, scope ...FilterOption) (toAdd, toDelete, toUpdate []RouteSpec, err error) {
+	if err := requireActiveStore(scope); err != nil {
+		return nil, nil, nil, err
+	}
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	live, err := getRoutes(cache, scope)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	liveByIdentity := make(map[routeIdentity]*Route, len(live))
+	for _, route := range live {
+		id := identityOf(route)
+		if _, exists := liveByIdentity[id]; !exists {
+			liveByIdentity[id] = route
+		}
+	}
+
+	wanted := make(map[routeIdentity]bool, len(desired))
+	ifaces := make(map[uint32]bool)
+	for _, spec := range desired {
+		id := specIdentity(spec)
+		if wanted[id] {
+			continue
+		}
+		wanted[id] = true
+		ifaces[spec.InterfaceIndex] = true
+
+		spec.Destination = id.destination
+		route, exists := liveByIdentity[id]
+		switch {
+		case !exists:
+			toAdd = append(toAdd, spec)
+		case route.Metric != spec.Metric:
+			toUpdate = append(toUpdate, spec)
+		}
+	}
+
+	// 按路由表顺序遍历，使计划的输出稳定
+	for _, route := range live {
+		id := identityOf(route)
+		if wanted[id] || !ifaces[id.ifaceIndex] || !route.isManageable() {
+			continue
+		}
+		wanted[id] = true // 重复的现有路由只删除一次
+		toDelete = append(toDelete, specOf(route))
+	}
+	return toAdd, toDelete, toUpdate, nil
+}
+
+// specOf 返回重新创建 route 所需的 RouteSpec。
+func specOf(route *Route) RouteSpec {
+	return RouteSpec{
+		Destination:    route.Destination,
+		NextHop:        route.NextHop.WithZone(""),
+		InterfaceIndex: route.Interface.Index,
+		Metric:         route.Metric,
+	}
+}
@@ -0,0 +1,52 @@
+package winroute
+
+// ProgressReporter 在批量操作处理完每条路由后报告进度，由 WithProgress 创建。
+// 可以作为选项传给 DeleteRoutes、DeleteRoutesBatch、AddRoutes 和 AddRoutesBatch。
+type ProgressReporter struct {
+	fn func(done, total int, current *Route)
+}
+
+// WithProgress 创建一个进度报告选项：每处理完一条路由（无论成功还是失败）调用一次 fn，
+// done 是已处理的路由数（从 1 开始），total 是本次要处理的路由总数，current 是刚处理的路由。
+// 批量添加时 current 由 RouteSpec 构造，只包含目标、下一跳、接口和 Metric。
+//
+// fn 在调用批量操作的 goroutine 中按顺序调用，不会并发执行；使用 ErrorActionStop 时，
+// 导致停止的那条路由同样会报告，之后不再调用。fn 为 nil 时不报告进度。
+func WithProgress(fn func(done, total int, current *Route)) ProgressReporter {
+	return ProgressReporter{fn: fn}
+}
+
+// withProgress 包装批量操作中对单条路由的操作 op，在每次 op 返回后报告进度。
+// toRoute 把批量操作的元素转换为报告给回调的路由。
+func withProgress[T any](p ProgressReporter, total int, toRoute func(T) *Route, op func(T) error) func(T) error {
+	if p.fn == nil {
+		return op
+	}
+	done := 0
+	return func(item T) error {
+		err := op(item)
+		done++
+		p.fn(done, total, toRoute(item))
+		return err
+	}
+}
+
+// routeOfSpec 把待添加的路由描述转换为用于报告进度的 Route。接口不在 cache 中时只填写接口索引。
+func routeOfSpec(cache *interfaceCache, spec RouteSpec) *Route {
+	iface, ok := cache.byIndex[spec.InterfaceIndex]
+	if !ok {
+		iface = &Interface{Index: spec.InterfaceIndex}
+	}
+	return &Route{
+		Destination:     spec.Destination,
+		NextHop:         spec.NextHop,
+		Interface:       iface,
+		Metric:          spec.Metric,
+		AutomaticMetric: spec.Metric == 0 && iface.usesAutomaticMetric(spec.Destination.Addr()),
+	}
+}
+
+// identityRoute 用于批量删除：报告给回调的就是被删除的路由本身。
+func identityRoute(route *Route) *Route {
+	return route
+}
@@ -0,0 +1,47 @@
+package winroute
+
+import (
+	"net/netip"
+
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// ---- routeProvider: 系统路由 API 的抽象 ----
+
+// routeProvider 抽象了本包用到的系统路由 API，使过滤、缓存和错误映射等逻辑可以在测试中
+// 使用伪实现，而不必修改真实的路由表。实现返回的错误应保持系统原始错误（如 windows.Errno），
+// 由调用方负责包装和映射。本包对路由和接口相关系统 API 的调用都应经过 provider。
+//
+// Windows 上的实现是 winipcfgProvider。其他平台上使用 unsupportedProvider，本包在那里只用于
+// 编译和运行与平台无关的测试；系统类型由 internal/winapi 提供可移植的替代定义。
+type routeProvider interface {
+	// interfaces 返回系统中的接口，包括各地址族是否启用及其自动 metric 设置。
+	// family 为 AF_UNSPEC 时返回全部接口，否则只返回启用了该地址族的接口及其该地址族的信息。
+	interfaces(family winapi.AddressFamily) ([]*Interface, error)
+	// routeTable 返回系统路由表中 family 地址族（AF_UNSPEC 表示全部）的原始行。
+	routeTable(family winapi.AddressFamily) ([]winapi.MibIPforwardRow2, error)
+	// createRoute 创建一条路由（CreateIpForwardEntry2）。
+	createRoute(row *winapi.MibIPforwardRow2) error
+	// deleteRoute 删除接口 luid 上精确匹配的路由（DeleteIpForwardEntry2）。
+	deleteRoute(luid winapi.LUID, destination netip.Prefix, nextHop netip.Addr) error
+	// luidFromIndex 将接口索引转换为 LUID（ConvertInterfaceIndexToLuid）。
+	luidFromIndex(index uint32) (winapi.LUID, error)
+	// indexFromLUID 将 LUID 转换为接口索引（GetIfEntry2）。
+	indexFromLUID(luid winapi.LUID) (uint32, error)
+	// route 读取接口 luid 上精确匹配的路由（GetIpForwardEntry2）。
+	route(luid winapi.LUID, destination netip.Prefix, nextHop netip.Addr) (*winapi.MibIPforwardRow2, error)
+	// setRoute 将修改后的路由行写回系统（SetIpForwardEntry2）。
+	setRoute(row *winapi.MibIPforwardRow2) error
+	// ipInterface 读取接口 luid 在 family 地址族上的 IP 接口行（GetIpInterfaceEntry）。
+	ipInterface(luid winapi.LUID, family winapi.AddressFamily) (*winapi.MibIPInterfaceRow, error)
+	// setIPInterface 将修改后的 IP 接口行写回系统（SetIpInterfaceEntry）。
+	setIPInterface(row *winapi.MibIPInterfaceRow) error
+	// watchInterfaces 订阅接口变化通知（NotifyIpInterfaceChange），返回取消订阅的函数。
+	watchInterfaces(callback func(winapi.MibNotificationType, *winapi.MibIPInterfaceRow)) (unregister func() error, err error)
+	// bestRoute 返回系统发往 destination 时使用的路由和源地址（GetBestRoute2）。
+	// destination 的 zone 必须是数字形式的接口索引。
+	bestRoute(destination netip.Addr) (*winapi.MibIPforwardRow2, netip.Addr, error)
+	// persistentRoutes 返回注册表中 route -p 记录的持久路由的值名称，格式见 internal/persistroute。
+	// 注册表项不存在（从未添加过持久路由）时返回空列表。
+	persistentRoutes() ([]string, error)
+}
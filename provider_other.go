@@ -0,0 +1,68 @@
+//go:build !windows
+
+package winroute
+
+import (
+	"errors"
+	"net/netip"
+
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// provider 是本包使用的 routeProvider，测试可以将其替换为伪实现。
+var provider routeProvider = unsupportedProvider{}
+
+// unsupportedProvider 在 Windows 以外的平台上使用，所有调用都返回 errors.ErrUnsupported。
+type unsupportedProvider struct{}
+
+func (unsupportedProvider) interfaces(winapi.AddressFamily) ([]*Interface, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupportedProvider) routeTable(winapi.AddressFamily) ([]winapi.MibIPforwardRow2, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupportedProvider) createRoute(*winapi.MibIPforwardRow2) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupportedProvider) deleteRoute(winapi.LUID, netip.Prefix, netip.Addr) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupportedProvider) luidFromIndex(uint32) (winapi.LUID, error) {
+	return 0, errors.ErrUnsupported
+}
+
+func (unsupportedProvider) indexFromLUID(winapi.LUID) (uint32, error) {
+	return 0, errors.ErrUnsupported
+}
+
+func (unsupportedProvider) route(winapi.LUID, netip.Prefix, netip.Addr) (*winapi.MibIPforwardRow2, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupportedProvider) setRoute(*winapi.MibIPforwardRow2) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupportedProvider) ipInterface(winapi.LUID, winapi.AddressFamily) (*winapi.MibIPInterfaceRow, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupportedProvider) setIPInterface(*winapi.MibIPInterfaceRow) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupportedProvider) watchInterfaces(func(winapi.MibNotificationType, *winapi.MibIPInterfaceRow)) (func() error, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupportedProvider) bestRoute(netip.Addr) (*winapi.MibIPforwardRow2, netip.Addr, error) {
+	return nil, netip.Addr{}, errors.ErrUnsupported
+}
+
+func (unsupportedProvider) persistentRoutes() ([]string, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,1823 @@
+package winroute
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// fakeProvider 是 routeProvider 的内存实现，测试不会访问系统路由表。
+//
+// 系统类型来自 internal/winapi，在非 Windows 平台上是可移植的替代定义，
+// 因此这些测试在所有平台上编译和运行。
+type fakeProvider struct {
+	ifaces []*Interface
+	rows   []winapi.MibIPforwardRow2
+
+	createErr error
+	deleteErr error
+	created   []winapi.MibIPforwardRow2
+	deleted   []netip.Prefix
+	families  []winapi.AddressFamily // 每次 routeTable 调用请求的地址族
+
+	ifaceFamilies []winapi.AddressFamily // 每次 interfaces 调用请求的地址族
+
+	setErr  error
+	watcher func(winapi.MibNotificationType, *winapi.MibIPInterfaceRow) // 当前订阅的接口变化回调
+
+	luidLookups int // luidFromIndex 的调用次数
+
+	persistent []string // 注册表中持久路由的值名称
+}
+
+func (f *fakeProvider) interfaces(family winapi.AddressFamily) ([]*Interface, error) {
+	f.ifaceFamilies = append(f.ifaceFamilies, family)
+	if family == winapi.AF_UNSPEC {
+		return f.ifaces, nil
+	}
+	var ifaces []*Interface
+	for _, iface := range f.ifaces {
+		if (family == winapi.AF_INET && iface.ipv4Enabled) || (family == winapi.AF_INET6 && iface.ipv6Enabled) {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	return ifaces, nil
+}
+
+func (f *fakeProvider) routeTable(family winapi.AddressFamily) ([]winapi.MibIPforwardRow2, error) {
+	f.families = append(f.families, family)
+	if family == winapi.AF_UNSPEC {
+		return f.rows, nil
+	}
+	var rows []winapi.MibIPforwardRow2
+	for _, row := range f.rows {
+		if row.DestinationPrefix.Prefix().Addr().Is4() == (family == winapi.AF_INET) {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeProvider) createRoute(row *winapi.MibIPforwardRow2) error {
+	if f.createErr != nil {
+		return f.createErr
+	}
+	f.created = append(f.created, *row)
+	return nil
+}
+
+func (f *fakeProvider) deleteRoute(luid winapi.LUID, destination netip.Prefix, nextHop netip.Addr) error {
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	f.deleted = append(f.deleted, destination)
+	return nil
+}
+
+func (f *fakeProvider) luidFromIndex(index uint32) (winapi.LUID, error) {
+	f.luidLookups++
+	for _, iface := range f.ifaces {
+		if iface.Index == index {
+			return iface.LUID, nil
+		}
+	}
+	return 0, winapi.ERROR_FILE_NOT_FOUND
+}
+
+func (f *fakeProvider) indexFromLUID(luid winapi.LUID) (uint32, error) {
+	for _, iface := range f.ifaces {
+		if iface.LUID == luid {
+			return iface.Index, nil
+		}
+	}
+	return 0, winapi.ERROR_FILE_NOT_FOUND
+}
+
+// bestRoute 以最长前缀匹配选择路由，源地址取出接口上第一个同族地址。
+func (f *fakeProvider) bestRoute(destination netip.Addr) (*winapi.MibIPforwardRow2, netip.Addr, error) {
+	var best *winapi.MibIPforwardRow2
+	for i := range f.rows {
+		row := &f.rows[i]
+		prefix := row.DestinationPrefix.Prefix()
+		if prefix.Contains(destination.WithZone("")) && (best == nil || prefix.Bits() > best.DestinationPrefix.Prefix().Bits()) {
+			best = row
+		}
+	}
+	if best == nil {
+		return nil, netip.Addr{}, winapi.ERROR_NETWORK_UNREACHABLE
+	}
+	for _, iface := range f.ifaces {
+		if iface.LUID != best.InterfaceLUID {
+			continue
+		}
+		for _, addr := range iface.Addresses {
+			if addr.Addr().Is4() == destination.Is4() {
+				return best, addr.Addr(), nil
+			}
+		}
+	}
+	return best, netip.Addr{}, nil
+}
+
+// findRow 返回接口 luid 上精确匹配的路由行，下一跳比较时忽略 zone。
+func (f *fakeProvider) findRow(luid winapi.LUID, destination netip.Prefix, nextHop netip.Addr) *winapi.MibIPforwardRow2 {
+	for i := range f.rows {
+		row := &f.rows[i]
+		if row.InterfaceLUID == luid && row.DestinationPrefix.Prefix() == destination &&
+			row.NextHop.Addr().WithZone("") == nextHop.WithZone("") {
+			return row
+		}
+	}
+	return nil
+}
+
+func (f *fakeProvider) route(luid winapi.LUID, destination netip.Prefix, nextHop netip.Addr) (*winapi.MibIPforwardRow2, error) {
+	row := f.findRow(luid, destination, nextHop)
+	if row == nil {
+		return nil, winapi.ERROR_NOT_FOUND
+	}
+	copied := *row
+	return &copied, nil
+}
+
+func (f *fakeProvider) setRoute(row *winapi.MibIPforwardRow2) error {
+	if f.setErr != nil {
+		return f.setErr
+	}
+	existing := f.findRow(row.InterfaceLUID, row.DestinationPrefix.Prefix(), row.NextHop.Addr())
+	if existing == nil {
+		return winapi.ERROR_NOT_FOUND
+	}
+	*existing = *row
+	return nil
+}
+
+// ipInterface 由接口的 metric 字段构造 IP 接口行，未启用的地址族返回 ERROR_NOT_FOUND。
+func (f *fakeProvider) ipInterface(luid winapi.LUID, family winapi.AddressFamily) (*winapi.MibIPInterfaceRow, error) {
+	for _, iface := range f.ifaces {
+		if iface.LUID != luid {
+			continue
+		}
+		row := &winapi.MibIPInterfaceRow{Family: family, InterfaceLUID: luid, InterfaceIndex: iface.Index}
+		switch {
+		case family == winapi.AF_INET && iface.ipv4Enabled:
+			row.Metric, row.UseAutomaticMetric = iface.metricV4, iface.automaticMetricV4
+		case family == winapi.AF_INET6 && iface.ipv6Enabled:
+			row.Metric, row.UseAutomaticMetric = iface.metricV6, iface.automaticMetricV6
+		default:
+			return nil, winapi.ERROR_NOT_FOUND
+		}
+		return row, nil
+	}
+	return nil, winapi.ERROR_FILE_NOT_FOUND
+}
+
+func (f *fakeProvider) setIPInterface(row *winapi.MibIPInterfaceRow) error {
+	if f.setErr != nil {
+		return f.setErr
+	}
+	for _, iface := range f.ifaces {
+		if iface.LUID != row.InterfaceLUID {
+			continue
+		}
+		if row.Family == winapi.AF_INET {
+			iface.metricV4, iface.automaticMetricV4 = row.Metric, row.UseAutomaticMetric
+		} else {
+			iface.metricV6, iface.automaticMetricV6 = row.Metric, row.UseAutomaticMetric
+		}
+		return nil
+	}
+	return winapi.ERROR_FILE_NOT_FOUND
+}
+
+func (f *fakeProvider) watchInterfaces(callback func(winapi.MibNotificationType, *winapi.MibIPInterfaceRow)) (func() error, error) {
+	f.watcher = callback
+	return func() error {
+		f.watcher = nil
+		return nil
+	}, nil
+}
+
+func (f *fakeProvider) persistentRoutes() ([]string, error) {
+	return f.persistent, nil
+}
+
+// useProvider 在测试期间用 f 替换 provider。
+func useProvider(t testing.TB, f *fakeProvider) {
+	t.Helper()
+	old := provider
+	provider = f
+	t.Cleanup(func() { provider = old })
+}
+
+func fakeRow(t testing.TB, luid winapi.LUID, destination, nextHop string, metric uint32) winapi.MibIPforwardRow2 {
+	t.Helper()
+	var row winapi.MibIPforwardRow2
+	row.InterfaceLUID = luid
+	if err := row.DestinationPrefix.SetPrefix(netip.MustParsePrefix(destination)); err != nil {
+		t.Fatal(err)
+	}
+	if err := row.NextHop.SetAddr(netip.MustParseAddr(nextHop)); err != nil {
+		t.Fatal(err)
+	}
+	row.Metric = metric
+	row.Protocol = winapi.RouteProtocolNetMgmt
+	row.ValidLifetime = 0xffffffff
+	row.PreferredLifetime = 0xffffffff
+	return row
+}
+
+const (
+	ethernetLUID winapi.LUID = 6<<48 | 1
+	chineseLUID  winapi.LUID = 6<<48 | 2
+	loopbackLUID winapi.LUID = winapi.LUID(winapi.IfTypeSoftwareLoopback)<<48 | 1
+)
+
+func newFakeProvider(t testing.TB) *fakeProvider {
+	return &fakeProvider{
+		ifaces: []*Interface{
+			{
+				Index: 5, LUID: ethernetLUID, Alias: "Ethernet", Description: "Realtek PCIe GbE Family Controller",
+				Addresses: []netip.Prefix{netip.MustParsePrefix("192.168.1.10/24")}, OperStatus: winapi.IfOperStatusUp,
+				IfType: winapi.IfTypeEthernetCSMACD, ipv4Enabled: true,
+			},
+			{
+				Index: 7, LUID: chineseLUID, Alias: "以太网", Description: "Intel(R) Ethernet Connection",
+				Addresses: []netip.Prefix{netip.MustParsePrefix("10.0.0.5/8")}, OperStatus: winapi.IfOperStatusUp,
+				IfType: winapi.IfTypeEthernetCSMACD, ipv4Enabled: true,
+			},
+			{
+				Index: 1, LUID: loopbackLUID, Alias: "Loopback Pseudo-Interface 1", Description: "Software Loopback Interface 1",
+				Addresses: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/8")}, OperStatus: winapi.IfOperStatusUp,
+				IfType: winapi.IfTypeSoftwareLoopback, ipv4Enabled: true,
+			},
+		},
+		rows: []winapi.MibIPforwardRow2{
+			fakeRow(t, ethernetLUID, "0.0.0.0/0", "192.168.1.1", 25),
+			fakeRow(t, ethernetLUID, "192.168.1.0/24", "0.0.0.0", 256),
+			fakeRow(t, chineseLUID, "10.20.0.0/16", "10.0.0.1", 10),
+			fakeRow(t, chineseLUID, "10.30.0.0/16", "172.16.0.1", 10),
+			fakeRow(t, loopbackLUID, "203.0.113.0/24", "0.0.0.0", 0),
+			fakeRow(t, loopbackLUID, "127.0.0.0/8", "0.0.0.0", 256),
+			// 接口不存在的路由会被跳过
+			fakeRow(t, 6<<48|99, "172.31.0.0/16", "0.0.0.0", 1),
+		},
+	}
+}
+
+func destinations(routes []*Route) []string {
+	dests := make([]string, 0, len(routes))
+	for _, route := range routes {
+		dests = append(dests, route.Destination.String())
+	}
+	return dests
+}
+
+func TestGetRoutesFilters(t *testing.T) {
+	useProvider(t, newFakeProvider(t))
+
+	tests := []struct {
+		name    string
+		filters []FilterOption
+		want    []string
+	}{
+		{
+			name: "no filter",
+			want: []string{"0.0.0.0/0", "192.168.1.0/24", "10.20.0.0/16", "10.30.0.0/16", "203.0.113.0/24", "127.0.0.0/8"},
+		},
+		{
+			name:    "destination prefix",
+			filters: []FilterOption{WithDestinationPrefix(netip.MustParsePrefix("10.20.0.0/16"))},
+			want:    []string{"10.20.0.0/16"},
+		},
+		{
+			name: "destination prefixes",
+			filters: []FilterOption{WithDestinationPrefixes(
+				netip.MustParsePrefix("10.30.0.0/16"),
+				netip.MustParsePrefix("0.0.0.0/0"),
+				netip.MustParsePrefix("10.99.0.0/16"),
+			)},
+			want: []string{"0.0.0.0/0", "10.30.0.0/16"},
+		},
+		{
+			name:    "mapped destination prefix",
+			filters: []FilterOption{WithDestinationPrefix(netip.MustParsePrefix("::ffff:10.20.0.0/112"))},
+			want:    []string{"10.20.0.0/16"},
+		},
+		{
+			name:    "mapped destination prefixes",
+			filters: []FilterOption{WithDestinationPrefixes(netip.MustParsePrefix("::ffff:10.30.0.0/112"))},
+			want:    []string{"10.30.0.0/16"},
+		},
+		{
+			name:    "mapped source address",
+			filters: []FilterOption{WithSourceAddress(netip.MustParseAddr("::ffff:10.0.0.5"))},
+			want:    []string{"10.20.0.0/16", "10.30.0.0/16"},
+		},
+		{
+			name:    "next hop",
+			filters: []FilterOption{WithNextHop(netip.MustParseAddr("10.0.0.1"))},
+			want:    []string{"10.20.0.0/16"},
+		},
+		{
+			name:    "on-link next hop",
+			filters: []FilterOption{WithInterfaceIndex(5), WithNextHop(netip.IPv4Unspecified())},
+			want:    []string{"192.168.1.0/24"},
+		},
+		{
+			name:    "no destination prefixes",
+			filters: []FilterOption{WithDestinationPrefixes()},
+			want:    []string{},
+		},
+		{
+			name:    "limit",
+			filters: []FilterOption{Limit(3), WithInterfaceIndex(7), Limit(1)},
+			want:    []string{"10.20.0.0/16"},
+		},
+		{
+			name:    "interface index",
+			filters: []FilterOption{WithInterfaceIndex(5)},
+			want:    []string{"0.0.0.0/0", "192.168.1.0/24"},
+		},
+		{
+			name:    "interface alias",
+			filters: []FilterOption{WithInterfaceAlias("以太网")},
+			want:    []string{"10.20.0.0/16", "10.30.0.0/16"},
+		},
+		{
+			name:    "interface alias pattern",
+			filters: []FilterOption{WithInterfaceAliasPattern("ETH*")},
+			want:    []string{"0.0.0.0/0", "192.168.1.0/24"},
+		},
+		{
+			name:    "interface alias prefix",
+			filters: []FilterOption{WithInterfaceAliasPrefix("eth")},
+			want:    []string{"0.0.0.0/0", "192.168.1.0/24"},
+		},
+		{
+			name:    "interface type",
+			filters: []FilterOption{WithInterfaceType(winapi.IfTypeSoftwareLoopback)},
+			want:    []string{"203.0.113.0/24", "127.0.0.0/8"},
+		},
+		{
+			name:    "interface description",
+			filters: []FilterOption{WithInterfaceDescription("realtek")},
+			want:    []string{"0.0.0.0/0", "192.168.1.0/24"},
+		},
+		{
+			name:    "metric and prefix length",
+			filters: []FilterOption{WithMetric(10), WithPrefixLength(16)},
+			want:    []string{"10.20.0.0/16", "10.30.0.0/16"},
+		},
+		{
+			name:    "without system routes",
+			filters: []FilterOption{WithInterfaceIndex(1), WithoutSystemRoutes()},
+			want:    []string{"203.0.113.0/24"},
+		},
+		{
+			name:    "blackhole",
+			filters: []FilterOption{WithBlackhole()},
+			want:    []string{"203.0.113.0/24"},
+		},
+		{
+			name:    "gateway routes",
+			filters: []FilterOption{WithGatewayRoutes()},
+			want:    []string{"0.0.0.0/0", "10.20.0.0/16", "10.30.0.0/16"},
+		},
+		{
+			name:    "next hop reachable",
+			filters: []FilterOption{WithInterfaceIndex(7), WithNextHopReachable()},
+			want:    []string{"10.20.0.0/16"},
+		},
+		{
+			name:    "destination in range",
+			filters: []FilterOption{WithDestinationInRange(netip.MustParseAddr("10.0.0.0"), netip.MustParseAddr("10.255.255.255"))},
+			want:    []string{"10.20.0.0/16", "10.30.0.0/16"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := GetRoutes(tt.filters...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := destinations(routes); !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetRoutesAddressFamily(t *testing.T) {
+	tests := []struct {
+		name       string
+		filters    []FilterOption
+		wantFamily winapi.AddressFamily
+		want       []string
+	}{
+		{
+			name:       "ipv4 table only",
+			filters:    []FilterOption{WithAddressFamily(winapi.AF_INET), WithInterfaceIndex(5)},
+			wantFamily: winapi.AF_INET,
+			want:       []string{"0.0.0.0/0", "192.168.1.0/24"},
+		},
+		{
+			name:       "ipv6 table only",
+			filters:    []FilterOption{WithInterfaceIndex(5), WithAddressFamily(winapi.AF_INET6)},
+			wantFamily: winapi.AF_INET6,
+			want:       []string{"::/0"},
+		},
+		{
+			name:       "conflicting families",
+			filters:    []FilterOption{WithAddressFamily(winapi.AF_INET), WithAddressFamily(winapi.AF_INET6)},
+			wantFamily: winapi.AF_INET,
+			want:       []string{},
+		},
+		{
+			name:       "no family filter",
+			filters:    []FilterOption{WithInterfaceIndex(5)},
+			wantFamily: winapi.AF_UNSPEC,
+			want:       []string{"0.0.0.0/0", "192.168.1.0/24", "::/0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeProvider(t)
+			f.rows = append(f.rows, fakeRow(t, ethernetLUID, "::/0", "fe80::1", 25))
+			useProvider(t, f)
+
+			routes, err := GetRoutes(tt.filters...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := destinations(routes); !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			if len(f.families) != 1 || f.families[0] != tt.wantFamily {
+				t.Fatalf("expected one table request for family %d, got %v", tt.wantFamily, f.families)
+			}
+		})
+	}
+
+	useProvider(t, newFakeProvider(t))
+	if _, err := GetRoutes(WithAddressFamily(winapi.AF_UNSPEC)); err == nil {
+		t.Fatal("expected an error for AF_UNSPEC")
+	}
+}
+
+func TestGetRoutesInvalidLimit(t *testing.T) {
+	useProvider(t, newFakeProvider(t))
+	if _, err := GetRoutes(Limit(0)); err == nil {
+		t.Fatal("expected an error for a zero limit")
+	}
+}
+
+func TestGetRoutesAmbiguousAlias(t *testing.T) {
+	f := newFakeProvider(t)
+	f.ifaces[1].Alias = "ETHERNET"
+	useProvider(t, f)
+
+	if _, err := GetRoutes(WithInterfaceAlias("Ethernet")); !errors.Is(err, ErrAmbiguousMatch) {
+		t.Fatalf("expected ErrAmbiguousMatch, got %v", err)
+	}
+}
+
+func TestGetRoutesUnknownAlias(t *testing.T) {
+	useProvider(t, newFakeProvider(t))
+
+	for _, filter := range []FilterOption{
+		WithInterfaceAlias("Ethernet2"),
+		WithInterfaceAliasIn("Ethernet", "Ethernet2"),
+		WithInterfaceAliasPattern("vEthernet *"),
+		WithInterfaceAliasPrefix("vEthernet"),
+	} {
+		if _, err := GetRoutes(filter); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+}
+
+func TestGetRoutesWithStore(t *testing.T) {
+	f := newFakeProvider(t)
+	f.persistent = []string{
+		"172.16.0.0,255.240.0.0,192.168.1.1,5",
+		"10.20.0.0,255.255.0.0,0.0.0.0,0",
+		"203.0.113.0,255.255.255.0,198.51.100.1,1", // 网关不在任何接口的子网中
+		"not a route",
+	}
+	useProvider(t, f)
+
+	all, err := GetRoutes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	active, err := GetRoutes(WithStore(StoreActive))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(destinations(active), destinations(all)) {
+		t.Fatalf("expected %v, got %v", destinations(all), destinations(active))
+	}
+
+	persistent, err := GetRoutes(WithStore(StorePersistent))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(persistent) != 2 {
+		t.Fatalf("expected 2 persistent routes, got %v", destinations(persistent))
+	}
+	if r := persistent[0]; r.Destination != netip.MustParsePrefix("172.16.0.0/12") ||
+		r.NextHop != netip.MustParseAddr("192.168.1.1") || r.Interface.Index != 5 || r.Metric != 5 {
+		t.Fatalf("unexpected route %v", r)
+	}
+	if r := persistent[1]; r.Destination != netip.MustParsePrefix("10.20.0.0/16") || !r.NextHop.IsUnspecified() || r.Interface.Index != 7 {
+		t.Fatalf("unexpected route %v", r)
+	}
+
+	// 其他过滤器同样作用于持久路由
+	filtered, err := GetRoutes(WithStore(StorePersistent), WithInterfaceIndex(7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].Destination != netip.MustParsePrefix("10.20.0.0/16") {
+		t.Fatalf("expected 10.20.0.0/16, got %v", destinations(filtered))
+	}
+	if v6, err := GetRoutes(WithStore(StorePersistent), WithAddressFamily(winapi.AF_INET6)); err != nil || len(v6) != 0 {
+		t.Fatalf("expected no IPv6 persistent routes, got %v, %v", v6, err)
+	}
+	if _, err := GetRoutes(WithStore(RouteStore(9))); err == nil {
+		t.Fatal("expected an error for an invalid store")
+	}
+}
+
+func TestModifyPersistentStoreRejected(t *testing.T) {
+	f := newFakeProvider(t)
+	f.persistent = []string{"172.16.0.0,255.240.0.0,192.168.1.1,5"}
+	useProvider(t, f)
+
+	if _, err := DeleteRoutes(WithStore(StorePersistent), WithInterfaceIndex(5)); err == nil {
+		t.Fatal("expected DeleteRoutes to reject the persistent store")
+	}
+	if _, _, err := SetMetricForRoutes(1, WithStore(StorePersistent)); err == nil {
+		t.Fatal("expected SetMetricForRoutes to reject the persistent store")
+	}
+	if _, _, _, err := PlanRoutes(nil, WithStore(StorePersistent)); err == nil {
+		t.Fatal("expected PlanRoutes to reject the persistent store")
+	}
+	if len(f.deleted) != 0 {
+		t.Fatalf("expected nothing to be deleted, got %v", f.deleted)
+	}
+}
+
+func TestGetRoutesDeduplicate(t *testing.T) {
+	f := newFakeProvider(t)
+	f.rows = append(f.rows, fakeRow(t, chineseLUID, "10.20.0.0/16", "10.0.0.1", 10))
+	useProvider(t, f)
+
+	routes, err := GetRoutes(WithInterfaceIndex(7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"10.20.0.0/16", "10.30.0.0/16", "10.20.0.0/16"}; !slices.Equal(destinations(routes), want) {
+		t.Fatalf("expected %v, got %v", want, destinations(routes))
+	}
+
+	routes, err = GetRoutes(WithInterfaceIndex(7), Deduplicate())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"10.20.0.0/16", "10.30.0.0/16"}; !slices.Equal(destinations(routes), want) {
+		t.Fatalf("expected %v, got %v", want, destinations(routes))
+	}
+}
+
+func TestMarshalRoutes(t *testing.T) {
+	data, err := MarshalRoutes(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "[]" {
+		t.Fatalf("expected [], got %s", data)
+	}
+
+	eth := &Interface{Index: 5, Alias: "Ethernet", Description: "Intel(R) Ethernet"}
+	route := &Route{
+		Destination: netip.MustParsePrefix("10.20.0.0/16"),
+		NextHop:     netip.MustParseAddr("192.168.1.1"),
+		Interface:   eth,
+		Metric:      25,
+		Protocol:    winapi.RouteProtocolNetMgmt,
+		Origin:      winapi.RouteOriginManual,
+	}
+	data, err = MarshalRoutes([]*Route{route})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	object := `{"destination":"10.20.0.0/16","next_hop":"192.168.1.1","metric":25,"interface_index":5,` +
+		`"interface_alias":"Ethernet","interface_description":"Intel(R) Ethernet","protocol":3,"origin":0}`
+	if want := "[" + object + "]"; string(data) != want {
+		t.Fatalf("expected %s, got %s", want, data)
+	}
+
+	data, err = json.Marshal(route)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != object {
+		t.Fatalf("expected %s, got %s", object, data)
+	}
+}
+
+func TestIndexLUIDConversion(t *testing.T) {
+	useProvider(t, newFakeProvider(t))
+
+	luid, err := IndexToLUID(7)
+	if err != nil || luid != chineseLUID {
+		t.Fatalf("IndexToLUID(7): expected %d, got %d, %v", chineseLUID, luid, err)
+	}
+	index, err := LUIDToIndex(chineseLUID)
+	if err != nil || index != 7 {
+		t.Fatalf("LUIDToIndex: expected 7, got %d, %v", index, err)
+	}
+
+	if _, err := IndexToLUID(42); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("IndexToLUID(42): expected ErrNotFound, got %v", err)
+	}
+	if _, err := LUIDToIndex(6<<48 | 99); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("LUIDToIndex: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGetRoutesWithStats(t *testing.T) {
+	useProvider(t, newFakeProvider(t))
+
+	routes, stats, err := GetRoutesWithStats(WithInterfaceIndex(7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.TableRoutes != 7 || stats.MatchedRoutes != len(routes) || len(routes) != 2 {
+		t.Fatalf("unexpected stats %+v for %d routes", stats, len(routes))
+	}
+	if stats.Total() < stats.FilterDuration {
+		t.Fatalf("total %s is less than filter duration %s", stats.Total(), stats.FilterDuration)
+	}
+}
+
+func TestRoutesHash(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	before, err := RoutesHash()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slices.Reverse(f.rows)
+	if after, _ := RoutesHash(); after != before {
+		t.Fatalf("expected the hash to ignore route order, got %#x and %#x", before, after)
+	}
+	f.rows[1].Metric++ // f.rows[0] 的接口不存在，不参与哈希
+	if after, _ := RoutesHash(); after == before {
+		t.Fatal("expected the hash to change with a metric")
+	}
+}
+
+func TestRoutesDependingOnInterface(t *testing.T) {
+	useProvider(t, newFakeProvider(t))
+
+	// 10.30.0.0/16 的网关 172.16.0.1 不在以太网（索引 7）的直连网段内，只能经由 Ethernet 的默认路由到达
+	routes, err := RoutesDependingOnInterface("Ethernet")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"0.0.0.0/0", "192.168.1.0/24", "10.30.0.0/16"}; !slices.Equal(destinations(routes), want) {
+		t.Fatalf("expected %v, got %v", want, destinations(routes))
+	}
+
+	routes, err = RoutesDependingOnInterface("7")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"10.20.0.0/16", "10.30.0.0/16"}; !slices.Equal(destinations(routes), want) {
+		t.Fatalf("expected %v, got %v", want, destinations(routes))
+	}
+
+	if _, err := RoutesDependingOnInterface("Ethernet2"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSuggestAggregations(t *testing.T) {
+	f := newFakeProvider(t)
+	f.rows = append(f.rows,
+		fakeRow(t, chineseLUID, "10.21.0.0/16", "10.0.0.1", 10),
+		fakeRow(t, chineseLUID, "10.20.5.0/24", "10.0.0.1", 10),
+		// metric 不同，不与 10.20.0.0/16 合并
+		fakeRow(t, chineseLUID, "10.22.0.0/16", "10.0.0.1", 20),
+		fakeRow(t, chineseLUID, "10.23.0.0/16", "10.0.0.1", 20),
+	)
+	useProvider(t, f)
+
+	suggestions, err := SuggestAggregations(WithInterfaceIndex(7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(suggestions) != 2 {
+		t.Fatalf("expected 2 suggestions, got %+v", suggestions)
+	}
+	first := suggestions[0]
+	if first.Supernet.String() != "10.20.0.0/15" || first.Metric != 10 || first.Interface.Index != 7 {
+		t.Fatalf("unexpected first suggestion %+v", first)
+	}
+	if want := []string{"10.20.0.0/16", "10.21.0.0/16", "10.20.5.0/24"}; !slices.Equal(destinations(first.Routes), want) {
+		t.Fatalf("expected %v, got %v", want, destinations(first.Routes))
+	}
+	if got := suggestions[1].Supernet.String(); got != "10.22.0.0/15" {
+		t.Fatalf("expected 10.22.0.0/15, got %s", got)
+	}
+}
+
+func TestGetInterfaceReport(t *testing.T) {
+	f := newFakeProvider(t)
+	f.ifaces[1].metricV4 = 35
+	f.ifaces[1].automaticMetricV4 = true
+	f.ifaces[1].Gateways = []netip.Addr{netip.MustParseAddr("10.0.0.1")}
+	useProvider(t, f)
+
+	report, err := GetInterfaceReport("以太网")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Interface.Index != 7 || report.Metric != 35 || !report.AutomaticMetric {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(report.Gateways) != 1 || report.Gateways[0] != netip.MustParseAddr("10.0.0.1") {
+		t.Fatalf("unexpected gateways %v", report.Gateways)
+	}
+	if want := []string{"10.20.0.0/16", "10.30.0.0/16"}; !slices.Equal(destinations(report.Routes), want) {
+		t.Fatalf("expected %v, got %v", want, destinations(report.Routes))
+	}
+
+	if _, err := GetInterfaceReport("99"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestUniqueFilters(t *testing.T) {
+	f := newFakeProvider(t)
+	// 与 10.20.0.0/16（以太网）的目标、下一跳和 metric 都相同，只有接口不同
+	f.rows = append(f.rows, fakeRow(t, ethernetLUID, "10.20.0.0/16", "10.0.0.1", 10))
+	useProvider(t, f)
+
+	routes, err := GetRoutes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		route     *Route
+		wantCount int
+	}{
+		{route: routes[1], wantCount: 1}, // 192.168.1.0/24 的目标前缀唯一
+		{route: routes[2], wantCount: 2}, // 10.20.0.0/16 还需要接口索引
+	}
+	for _, tt := range tests {
+		filters, err := UniqueFilters(tt.route.Clone(), routes)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.route, err)
+		}
+		if len(filters) != tt.wantCount {
+			t.Fatalf("%s: expected %d filters, got %d", tt.route, tt.wantCount, len(filters))
+		}
+		matched, err := GetRoutes(filters...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(matched) != 1 || !matched[0].Equal(tt.route) {
+			t.Fatalf("expected the filters to match only %s, got %v", tt.route, matched)
+		}
+	}
+}
+
+func TestRoutesSetOperations(t *testing.T) {
+	useProvider(t, newFakeProvider(t))
+
+	a, err := GetRoutes(WithInterfaceIndex(5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := GetRoutes(WithPrefixLength(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 另一次查询得到的相同路由
+	b = append(b, a[1].Clone())
+
+	if got, want := destinations(RoutesIntersection(a, b)), []string{"0.0.0.0/0", "192.168.1.0/24"}; !slices.Equal(got, want) {
+		t.Fatalf("intersection: expected %v, got %v", want, got)
+	}
+	if got := RoutesDifference(a, b); len(got) != 0 {
+		t.Fatalf("difference: expected nothing, got %v", destinations(got))
+	}
+
+	c, err := GetRoutes(WithInterfaceIndex(7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := destinations(RoutesUnion(a, append(c, b...))), []string{"0.0.0.0/0", "192.168.1.0/24", "10.20.0.0/16", "10.30.0.0/16"}; !slices.Equal(got, want) {
+		t.Fatalf("union: expected %v, got %v", want, got)
+	}
+}
+
+func TestDiffRoutes(t *testing.T) {
+	eth := &Interface{Index: 5}
+	route := func(destination string, metric uint32) *Route {
+		return &Route{
+			Destination: netip.MustParsePrefix(destination),
+			NextHop:     netip.MustParseAddr("192.168.1.1"),
+			Interface:   eth,
+			Metric:      metric,
+		}
+	}
+
+	tests := []struct {
+		name                    string
+		oldRoutes, newRoutes    []*Route
+		added, removed, changed []string
+	}{
+		{
+			name:      "identical",
+			oldRoutes: []*Route{route("10.0.0.0/8", 10)},
+			newRoutes: []*Route{route("10.0.0.0/8", 10)},
+		},
+		{
+			name:      "added and removed",
+			oldRoutes: []*Route{route("10.0.0.0/8", 10)},
+			newRoutes: []*Route{route("172.16.0.0/12", 10)},
+			added:     []string{"172.16.0.0/12"},
+			removed:   []string{"10.0.0.0/8"},
+		},
+		{
+			name:      "metric changed",
+			oldRoutes: []*Route{route("10.0.0.0/8", 10), route("0.0.0.0/0", 25)},
+			newRoutes: []*Route{route("10.0.0.0/8", 50), route("0.0.0.0/0", 25)},
+			changed:   []string{"10.0.0.0/8"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed, changed := DiffRoutes(tt.oldRoutes, tt.newRoutes)
+			if got := destinations(added); !slices.Equal(got, tt.added) {
+				t.Fatalf("added: expected %v, got %v", tt.added, got)
+			}
+			if got := destinations(removed); !slices.Equal(got, tt.removed) {
+				t.Fatalf("removed: expected %v, got %v", tt.removed, got)
+			}
+			if got := destinations(changed); !slices.Equal(got, tt.changed) {
+				t.Fatalf("changed: expected %v, got %v", tt.changed, got)
+			}
+		})
+	}
+}
+
+func TestDeleteRouteErrorMapping(t *testing.T) {
+	tests := []struct {
+		name      string
+		deleteErr error
+		ifIndex   uint32
+		want      error
+	}{
+		{name: "success", ifIndex: 5},
+		{name: "not found", deleteErr: winapi.ERROR_NOT_FOUND, ifIndex: 5, want: ErrNotFound},
+		{name: "access denied", deleteErr: winapi.ERROR_ACCESS_DENIED, ifIndex: 5, want: ErrAccessDenied},
+		{name: "other error kept", deleteErr: winapi.ERROR_INVALID_PARAMETER, ifIndex: 5, want: winapi.ERROR_INVALID_PARAMETER},
+		{name: "unknown interface", ifIndex: 42, want: winapi.ERROR_FILE_NOT_FOUND},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeProvider(t)
+			f.deleteErr = tt.deleteErr
+			useProvider(t, f)
+
+			err := DeleteRoute(netip.MustParsePrefix("10.20.0.0/16"), netip.MustParseAddr("10.0.0.1"), tt.ifIndex)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if tt.want != ErrAccessDenied && errors.Is(err, ErrAccessDenied) {
+				t.Fatalf("unexpected ErrAccessDenied: %v", err)
+			}
+		})
+	}
+}
+
+func TestDeleteRoutesBatch(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	result, err := DeleteRoutesBatch(WithInterfaceIndex(7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Succeeded != 2 || result.HasErrors() {
+		t.Fatalf("expected 2 successful deletions, got %+v", result)
+	}
+
+	f.deleteErr = winapi.ERROR_ACCESS_DENIED
+	result, err = DeleteRoutesBatch(WithInterfaceIndex(7), ErrorActionStop)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Succeeded != 0 || result.Failed != 1 || result.Errors[0].Op != "delete" {
+		t.Fatalf("expected the first failure to stop the batch, got %+v", result)
+	}
+	var asErr error = result
+	if !errors.Is(asErr, ErrAccessDenied) {
+		t.Fatalf("expected the result to wrap ErrAccessDenied, got %v", asErr)
+	}
+
+	_, err = DeleteRoutes(WithInterfaceIndex(7), ErrorActionStop)
+	var routeErr *RouteError
+	if !errors.As(err, &routeErr) || routeErr.Op != "delete" || !errors.Is(routeErr, ErrAccessDenied) {
+		t.Fatalf("expected DeleteRoutes to return a *RouteError, got %v", err)
+	}
+
+	if _, err := DeleteRoutesBatch(); !errors.Is(err, ErrNoFilter) {
+		t.Fatalf("expected ErrNoFilter, got %v", err)
+	}
+}
+
+func TestDeleteRoutesQueryOptionsAreNotFilters(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	for _, opt := range []FilterOption{Deduplicate(), IncludeRawRow(), Limit(1), WithAddressFamily(winapi.AF_INET), WithStore(StoreActive)} {
+		if _, err := DeleteRoutes(opt); !errors.Is(err, ErrNoFilter) {
+			t.Fatalf("expected ErrNoFilter, got %v", err)
+		}
+	}
+	if len(f.deleted) != 0 {
+		t.Fatalf("expected nothing to be deleted, got %v", f.deleted)
+	}
+
+	if _, err := DeleteRoutes(Deduplicate(), WithInterfaceIndex(7)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.deleted) != 2 {
+		t.Fatalf("expected the filtered routes to be deleted, got %v", f.deleted)
+	}
+}
+
+func TestBatchProgress(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	var reported []string
+	progress := WithProgress(func(done, total int, current *Route) {
+		if done != len(reported)+1 || total != 2 {
+			t.Fatalf("unexpected progress %d/%d", done, total)
+		}
+		reported = append(reported, current.Destination.String())
+	})
+	if _, err := DeleteRoutes(WithInterfaceIndex(7), progress); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"10.20.0.0/16", "10.30.0.0/16"}; !slices.Equal(reported, want) {
+		t.Fatalf("expected progress for %v, got %v", want, reported)
+	}
+
+	// 失败的路由同样报告进度，ErrorActionStop 之后不再报告
+	reported = nil
+	f.createErr = winapi.ERROR_ACCESS_DENIED
+	specs := []RouteSpec{
+		{Destination: netip.MustParsePrefix("10.40.0.0/16"), NextHop: netip.MustParseAddr("192.168.1.1"), InterfaceIndex: 5},
+		{Destination: netip.MustParsePrefix("10.50.0.0/16"), NextHop: netip.MustParseAddr("192.168.1.1"), InterfaceIndex: 5},
+	}
+	if _, err := AddRoutes(specs, progress, ErrorActionStop); err == nil {
+		t.Fatal("expected an error")
+	}
+	if want := []string{"10.40.0.0/16"}; !slices.Equal(reported, want) {
+		t.Fatalf("expected progress for %v, got %v", want, reported)
+	}
+}
+
+func TestBatchRateLimit(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	specs := []RouteSpec{
+		{Destination: netip.MustParsePrefix("10.40.0.0/16"), NextHop: netip.MustParseAddr("192.168.1.1"), InterfaceIndex: 5},
+		{Destination: netip.MustParsePrefix("10.50.0.0/16"), NextHop: netip.MustParseAddr("192.168.1.1"), InterfaceIndex: 5},
+		{Destination: netip.MustParsePrefix("10.60.0.0/16"), NextHop: netip.MustParseAddr("192.168.1.1"), InterfaceIndex: 5},
+	}
+	start := time.Now()
+	if partialErrs, err := AddRoutes(specs, RateLimit(50)); err != nil || partialErrs != nil {
+		t.Fatalf("unexpected errors: %v, %v", partialErrs, err)
+	}
+	// 三条路由之间有两个 20ms 的间隔
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("expected the batch to take at least 40ms, took %s", elapsed)
+	}
+	if len(f.created) != 3 {
+		t.Fatalf("expected 3 created routes, got %d", len(f.created))
+	}
+
+	if _, err := DeleteRoutes(WithInterfaceIndex(7), RateLimit(0)); err == nil {
+		t.Fatal("expected an error for a non-positive rate limit")
+	}
+}
+
+func TestAddRouteErrorMapping(t *testing.T) {
+	tests := []struct {
+		name      string
+		createErr error
+		want      error
+	}{
+		{name: "success"},
+		{name: "already exists", createErr: winapi.ERROR_OBJECT_ALREADY_EXISTS, want: winapi.ERROR_OBJECT_ALREADY_EXISTS},
+		{name: "access denied", createErr: winapi.ERROR_ACCESS_DENIED, want: ErrAccessDenied},
+		{name: "invalid parameter", createErr: winapi.ERROR_INVALID_PARAMETER, want: winapi.ERROR_INVALID_PARAMETER},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeProvider(t)
+			f.createErr = tt.createErr
+			useProvider(t, f)
+
+			err := AddRoute(netip.MustParsePrefix("10.40.0.5/16"), netip.MustParseAddr("192.168.1.1"), 5, 30)
+			if tt.want != nil {
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("expected %v, got %v", tt.want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(f.created) != 1 {
+				t.Fatalf("expected one created route, got %d", len(f.created))
+			}
+			row := f.created[0]
+			if row.InterfaceLUID != ethernetLUID || row.Metric != 30 ||
+				row.DestinationPrefix.Prefix() != netip.MustParsePrefix("10.40.0.0/16") {
+				t.Fatalf("unexpected created row: luid %d, metric %d, destination %s",
+					row.InterfaceLUID, row.Metric, row.DestinationPrefix.Prefix())
+			}
+		})
+	}
+}
+
+func TestDeleteRoutesFunc(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	deleted, partialErrs, err := DeleteRoutesFunc(func(r *Route) bool {
+		return r.Metric == 10
+	})
+	if err != nil || len(partialErrs) > 0 {
+		t.Fatalf("unexpected errors: %v, %v", err, partialErrs)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 deleted routes, got %d", deleted)
+	}
+	want := []netip.Prefix{netip.MustParsePrefix("10.20.0.0/16"), netip.MustParsePrefix("10.30.0.0/16")}
+	if !slices.Equal(f.deleted, want) {
+		t.Fatalf("expected %v, got %v", want, f.deleted)
+	}
+
+	f.deleteErr = winapi.ERROR_ACCESS_DENIED
+	deleted, partialErrs, err = DeleteRoutesFunc(func(r *Route) bool { return true }, ErrorActionStop)
+	if deleted != 0 || partialErrs != nil || !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("expected access denied after 0 deletions, got %d, %v, %v", deleted, partialErrs, err)
+	}
+}
+
+func TestAddRouteToInterfaces(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	added, partialErrs, err := AddRouteToInterfaces(netip.MustParsePrefix("10.50.0.0/16"), netip.Addr{}, "*E*", 40)
+	if err != nil || len(partialErrs) > 0 {
+		t.Fatalf("unexpected errors: %v, %v", err, partialErrs)
+	}
+	if added != 2 || len(f.created) != 2 {
+		t.Fatalf("expected 2 routes, got %d added, %d created", added, len(f.created))
+	}
+	if f.created[0].InterfaceLUID != ethernetLUID || f.created[1].InterfaceLUID != loopbackLUID {
+		t.Fatalf("unexpected interfaces %d, %d", f.created[0].InterfaceLUID, f.created[1].InterfaceLUID)
+	}
+
+	if _, _, err := AddRouteToInterfaces(netip.MustParsePrefix("10.50.0.0/16"), netip.Addr{}, "vEthernet *", 40); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAddExpiringRoute(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	err := AddExpiringRoute(netip.MustParsePrefix("10.60.0.0/16"), netip.MustParseAddr("192.168.1.1"), 5, 20, 90*time.Second+time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.created) != 1 {
+		t.Fatalf("expected one created route, got %d", len(f.created))
+	}
+	if row := f.created[0]; row.ValidLifetime != 91 || row.PreferredLifetime != 91 {
+		t.Fatalf("expected lifetimes of 91s, got valid %d, preferred %d", row.ValidLifetime, row.PreferredLifetime)
+	}
+
+	if err := AddExpiringRoute(netip.MustParsePrefix("10.60.0.0/16"), netip.Addr{}, 5, 20, 0); err == nil {
+		t.Fatal("expected an error for a zero ttl")
+	}
+}
+
+func TestAddRoutePreferredSource(t *testing.T) {
+	tests := []struct {
+		source  string
+		wantErr bool
+	}{
+		{source: "192.168.1.10"},
+		{source: "10.0.0.5", wantErr: true},
+		{source: "2001:db8::10", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			f := newFakeProvider(t)
+			useProvider(t, f)
+
+			err := AddRouteSpec(RouteSpec{
+				Destination:     netip.MustParsePrefix("10.70.0.0/16"),
+				NextHop:         netip.MustParseAddr("192.168.1.1"),
+				InterfaceIndex:  5,
+				PreferredSource: netip.MustParseAddr(tt.source),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if created := len(f.created) == 1; created == tt.wantErr {
+				t.Fatalf("expected the route to be created only without an error, created %d", len(f.created))
+			}
+		})
+	}
+}
+
+func TestAddRouteRequireInterfaceUp(t *testing.T) {
+	f := newFakeProvider(t)
+	f.ifaces[1].OperStatus = winapi.IfOperStatusDown
+	useProvider(t, f)
+
+	destination := netip.MustParsePrefix("10.40.0.0/16")
+	if err := AddRoute(destination, netip.MustParseAddr("10.0.0.1"), 7, 0, RequireInterfaceUp); !errors.Is(err, ErrInterfaceDown) {
+		t.Fatalf("expected ErrInterfaceDown, got %v", err)
+	}
+	if len(f.created) != 0 {
+		t.Fatalf("expected no route to be created, got %d", len(f.created))
+	}
+
+	// 默认不检查接口状态
+	if err := AddRoute(destination, netip.MustParseAddr("10.0.0.1"), 7, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := AddRoute(destination, netip.MustParseAddr("192.168.1.1"), 5, 0, RequireInterfaceUp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.created) != 2 {
+		t.Fatalf("expected 2 created routes, got %d", len(f.created))
+	}
+}
+
+func TestAddRouteOnLink(t *testing.T) {
+	tests := []struct {
+		destination string
+		want        netip.Addr
+	}{
+		{destination: "203.0.113.7/32", want: netip.IPv4Unspecified()},
+		{destination: "2001:db8::/64", want: netip.IPv6Unspecified()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			f := newFakeProvider(t)
+			useProvider(t, f)
+
+			if err := AddRoute(netip.MustParsePrefix(tt.destination), netip.Addr{}, 5, 0); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(f.created) != 1 {
+				t.Fatalf("expected one created route, got %d", len(f.created))
+			}
+			if got := f.created[0].NextHop.Addr(); got != tt.want {
+				t.Fatalf("expected next hop %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAddRouteIndexReuse(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+	table, err := NewRouteTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 适配器重连后，Ethernet 获得新索引 12，原索引 5 被另一个适配器复用
+	ethernet, chinese := f.ifaces[0].clone(), f.ifaces[1].clone()
+	ethernet.Index, chinese.Index = 12, 5
+	f.ifaces = []*Interface{ethernet, chinese, f.ifaces[2]}
+
+	if err := table.Add(RouteSpec{Destination: netip.MustParsePrefix("10.60.0.0/16"), InterfaceIndex: 5}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.created[0].InterfaceLUID; got != chineseLUID {
+		t.Fatalf("expected the stale cache to be bypassed (LUID %d), got %d", chineseLUID, got)
+	}
+	builds := len(f.ifaceFamilies)
+	if err := table.Add(RouteSpec{Destination: netip.MustParsePrefix("10.63.0.0/16"), InterfaceIndex: 12}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(f.ifaceFamilies); got != builds {
+		t.Fatalf("expected the refreshed cache to be kept by the route table, got %d more builds", got-builds)
+	}
+
+	if err := AddRouteByLUID(netip.MustParsePrefix("10.61.0.0/16"), netip.Addr{}, ethernetLUID, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.created[1].InterfaceLUID; got != ethernetLUID {
+		t.Fatalf("expected LUID %d, got %d", ethernetLUID, got)
+	}
+
+	if err := AddRouteByLUID(netip.MustParsePrefix("10.62.0.0/16"), netip.Addr{}, 6<<48|99, 0); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unknown LUID, got %v", err)
+	}
+}
+
+func TestAddRoutesRebuildsStaleCacheOnce(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	// 第一条路由添加后适配器重连：Ethernet 获得新索引 12，原索引 5 被另一个适配器复用
+	progress := WithProgress(func(done, total int, current *Route) {
+		if done == 1 {
+			ethernet, chinese := f.ifaces[0].clone(), f.ifaces[1].clone()
+			ethernet.Index, chinese.Index = 12, 5
+			f.ifaces = []*Interface{ethernet, chinese, f.ifaces[2]}
+		}
+	})
+	var specs []RouteSpec
+	for i := range 4 {
+		specs = append(specs, RouteSpec{
+			Destination:    netip.PrefixFrom(netip.AddrFrom4([4]byte{10, 70, byte(i), 0}), 24),
+			InterfaceIndex: 5,
+		})
+	}
+	partialErrs, err := AddRoutes(specs, progress)
+	if err != nil || len(partialErrs) != 0 {
+		t.Fatalf("unexpected errors: %v, %v", err, partialErrs)
+	}
+	if got := len(f.ifaceFamilies); got != 2 {
+		t.Fatalf("expected the cache to be built once and rebuilt once, got %d builds", got)
+	}
+	for i, row := range f.created[1:] {
+		if row.InterfaceLUID != chineseLUID {
+			t.Fatalf("route %d: expected LUID %d, got %d", i+1, chineseLUID, row.InterfaceLUID)
+		}
+	}
+}
+
+func TestAddRouteMappedAddresses(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	destination := netip.MustParsePrefix("::ffff:10.40.0.0/112")
+	nextHop := netip.MustParseAddr("::ffff:192.168.1.1")
+	if err := AddRoute(destination, nextHop, 5, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	row := f.created[0]
+	if got := row.DestinationPrefix.Prefix(); got != netip.MustParsePrefix("10.40.0.0/16") {
+		t.Fatalf("expected destination 10.40.0.0/16, got %s", got)
+	}
+	if got := row.NextHop.Addr(); got != netip.MustParseAddr("192.168.1.1") {
+		t.Fatalf("expected next hop 192.168.1.1, got %s", got)
+	}
+
+	if err := DeleteRoute(netip.MustParsePrefix("::ffff:10.20.0.0/112"), netip.MustParseAddr("::ffff:10.0.0.1"), 7); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.deleted[0]; got != netip.MustParsePrefix("10.20.0.0/16") {
+		t.Fatalf("expected 10.20.0.0/16 to be deleted, got %s", got)
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+	if err := SelfTest(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 路由全部指向未知接口时说明接口枚举与路由表不一致
+	f.rows = f.rows[len(f.rows)-1:]
+	if err := SelfTest(); err == nil {
+		t.Fatal("expected an error when no route resolves to an interface")
+	}
+
+	f.ifaces = nil
+	if err := SelfTest(); err == nil {
+		t.Fatal("expected an error without interfaces")
+	}
+}
+
+func TestDumpRawTable(t *testing.T) {
+	useProvider(t, newFakeProvider(t))
+	var buf bytes.Buffer
+	if err := DumpRawTable(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"7 rows\n",
+		"address=10.20.0.0 length=16",
+		"address=10.0.0.1\n",
+		// 接口不存在的路由同样输出
+		"address=172.31.0.0 length=16",
+		"  Metric                256\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected the dump to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestPickFastestInterface(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+	if _, err := PickFastestInterface(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound without known speeds, got %v", err)
+	}
+
+	f.ifaces[0].TransmitSpeed, f.ifaces[0].ReceiveSpeed = 1_000_000_000, 1_000_000_000
+	f.ifaces[1].TransmitSpeed, f.ifaces[1].ReceiveSpeed = 1_000_000_000, 2_500_000_000
+	f.ifaces[2].TransmitSpeed, f.ifaces[2].ReceiveSpeed = 1<<40, 1<<40
+	iface, err := PickFastestInterface()
+	if err != nil || iface.Index != 7 {
+		t.Fatalf("expected interface 7 (faster receive, loopback ignored), got %v, %v", iface, err)
+	}
+	if got := iface.SpeedName(); got != "1 Gbps / 2.5 Gbps" {
+		t.Fatalf("unexpected speed name %q", got)
+	}
+
+	f.ifaces[1].OperStatus = winapi.IfOperStatusDown
+	if iface, err := PickFastestInterface(); err != nil || iface.Index != 5 {
+		t.Fatalf("expected the down interface to be skipped, got %v, %v", iface, err)
+	}
+}
+
+func TestFindBestRouteUsesInterfaceMetric(t *testing.T) {
+	f := newFakeProvider(t)
+	// 同一前缀在两个接口上：路由 metric 相同时由接口 metric 决定
+	f.rows = append(f.rows,
+		fakeRow(t, ethernetLUID, "172.16.0.0/12", "192.168.1.1", 5),
+		fakeRow(t, chineseLUID, "172.16.0.0/12", "10.0.0.1", 5),
+	)
+	f.ifaces[0].metricV4 = 50
+	f.ifaces[1].metricV4 = 10
+	useProvider(t, f)
+
+	addr := netip.MustParseAddr("172.16.1.1")
+	route, err := FindBestRoute(addr)
+	if err != nil || route.Interface.Index != 7 {
+		t.Fatalf("expected interface 7 (5+10 < 5+50), got %v, %v", route, err)
+	}
+
+	// 未运行的接口上的路由不参与选择
+	f.ifaces[1].OperStatus = winapi.IfOperStatusDown
+	if iface, err := GetInterfaceForDestination(addr); err != nil || iface.Index != 5 {
+		t.Fatalf("expected the down interface to be skipped, got %v, %v", iface, err)
+	}
+}
+
+func TestGatewayFor(t *testing.T) {
+	useProvider(t, newFakeProvider(t))
+
+	tests := []struct {
+		addr      string
+		wantHop   string
+		wantIndex uint32
+	}{
+		{addr: "10.20.5.5", wantHop: "10.0.0.1", wantIndex: 7},
+		{addr: "192.168.1.77", wantHop: "0.0.0.0", wantIndex: 5},
+		{addr: "198.51.100.1", wantHop: "192.168.1.1", wantIndex: 5},
+	}
+	for _, tt := range tests {
+		nextHop, iface, err := GatewayFor(netip.MustParseAddr(tt.addr))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.addr, err)
+		}
+		if nextHop.String() != tt.wantHop || iface.Index != tt.wantIndex {
+			t.Fatalf("%s: expected %s on %d, got %s on %d", tt.addr, tt.wantHop, tt.wantIndex, nextHop, iface.Index)
+		}
+	}
+
+	if _, _, err := GatewayFor(netip.MustParseAddr("2001:db8::1")); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestResolveOutbound(t *testing.T) {
+	useProvider(t, newFakeProvider(t))
+
+	source, route, err := ResolveOutbound(netip.MustParseAddr("::ffff:10.20.5.5"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source != netip.MustParseAddr("10.0.0.5") || route.Destination.String() != "10.20.0.0/16" || route.Interface.Index != 7 {
+		t.Fatalf("unexpected result %s via %s", source, route)
+	}
+
+	if _, _, err := ResolveOutbound(netip.MustParseAddr("2001:db8::1")); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, _, err := ResolveOutbound(netip.MustParseAddr("fe80::1%Ethernet2")); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unknown zone, got %v", err)
+	}
+}
+
+func TestPrimaryInterfaceUsesInterfaceMetric(t *testing.T) {
+	f := newFakeProvider(t)
+	// DHCP 默认路由的路由 metric 为 0，但接口 metric 更大，整体优先级低于 Ethernet
+	f.rows = append(f.rows, fakeRow(t, chineseLUID, "0.0.0.0/0", "10.0.0.1", 0))
+	f.ifaces[0].metricV4 = 10
+	f.ifaces[1].metricV4 = 100
+	useProvider(t, f)
+
+	iface, err := PrimaryInterface()
+	if err != nil || iface.Index != 5 {
+		t.Fatalf("expected interface 5 (25+10 < 0+100), got %v, %v", iface, err)
+	}
+
+	f.ifaces[1].metricV4 = 20
+	if iface, err := PrimaryInterface(); err != nil || iface.Index != 7 {
+		t.Fatalf("expected interface 7 (0+20 < 25+10), got %v, %v", iface, err)
+	}
+}
+
+func TestWaitForDefaultRoute(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	route, err := WaitForDefaultRoute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if route.Destination.String() != "0.0.0.0/0" || route.Interface.Index != 5 {
+		t.Fatalf("unexpected default route %v", route)
+	}
+
+	// 默认路由所在的接口停止运行时一直等待，直到 ctx 结束
+	for _, iface := range f.ifaces {
+		if iface.Index == 5 {
+			iface.OperStatus = winapi.IfOperStatusDown
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := WaitForDefaultRoute(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestNewRouteTableFamily(t *testing.T) {
+	f := newFakeProvider(t)
+	f.ifaces[2].ipv4Enabled = false
+	useProvider(t, f)
+
+	table, err := NewRouteTableFamily(winapi.AF_INET)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []winapi.AddressFamily{winapi.AF_INET}; !slices.Equal(f.ifaceFamilies, want) {
+		t.Fatalf("expected interfaces to be requested for %v, got %v", want, f.ifaceFamilies)
+	}
+	if _, ok := table.cache.byIndex[1]; ok {
+		t.Fatal("expected the interface without IPv4 to be left out of the cache")
+	}
+
+	if _, err := table.GetRoutes(WithInterfaceIndex(5)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.families[len(f.families)-1]; got != winapi.AF_INET {
+		t.Fatalf("expected the route table to be requested for AF_INET, got %v", got)
+	}
+	routes, err := table.GetRoutes(WithAddressFamily(winapi.AF_INET6))
+	if err != nil || len(routes) != 0 {
+		t.Fatalf("expected no routes of the other family, got %v, %v", routes, err)
+	}
+
+	err = table.Add(RouteSpec{Destination: netip.MustParsePrefix("2001:db8::/32"), InterfaceIndex: 5})
+	if err == nil || len(f.created) != 0 {
+		t.Fatalf("expected a route of the other family to be rejected, got %v", err)
+	}
+
+	if err := table.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.ifaceFamilies[len(f.ifaceFamilies)-1]; got != winapi.AF_INET {
+		t.Fatalf("expected Refresh to keep the family hint, got %v", got)
+	}
+
+	if _, err := NewRouteTableFamily(winapi.AddressFamily(99)); err == nil {
+		t.Fatal("expected an error for an unsupported family")
+	}
+}
+
+func TestRouteTableMyRoutesLinkLocalNextHop(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+	table, err := NewRouteTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spec := RouteSpec{
+		Destination:    netip.MustParsePrefix("2001:db8:1::/48"),
+		NextHop:        netip.MustParseAddr("fe80::1%Ethernet"),
+		InterfaceIndex: 5,
+	}
+	if err := table.Add(spec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 系统读回的下一跳带有数字形式的 zone
+	f.rows = append(f.rows, f.created...)
+
+	mine, err := table.MyRoutes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := destinations(mine); !slices.Equal(got, []string{"2001:db8:1::/48"}) {
+		t.Fatalf("expected the zoned route to be tracked, got %v", got)
+	}
+	if zone := mine[0].NextHop.Zone(); zone != "5" {
+		t.Fatalf("expected the route read back with zone 5, got %q", zone)
+	}
+}
+
+func TestInterfaceMetric(t *testing.T) {
+	f := newFakeProvider(t)
+	f.ifaces[0].metricV4, f.ifaces[0].automaticMetricV4 = 25, true
+	useProvider(t, f)
+
+	metric, automatic, err := GetInterfaceMetric(5)
+	if err != nil || metric != 25 || !automatic {
+		t.Fatalf("expected automatic metric 25, got %d, %t, %v", metric, automatic, err)
+	}
+	if err := SetInterfaceMetric(5, 40); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if metric, automatic, _ := GetInterfaceMetric(5); metric != 40 || automatic {
+		t.Fatalf("expected fixed metric 40, got %d, %t", metric, automatic)
+	}
+	if recommended, err := RecommendedMetric(5); err != nil || recommended != 40 {
+		t.Fatalf("expected recommended metric 40, got %d, %v", recommended, err)
+	}
+
+	f.setErr = winapi.ERROR_ACCESS_DENIED
+	if err := SetInterfaceMetric(5, 50); !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("expected ErrAccessDenied, got %v", err)
+	}
+	if _, _, err := GetInterfaceMetric(42); err == nil {
+		t.Fatal("expected an error for an unknown interface")
+	}
+}
+
+func TestAdjustRouteMetric(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	if err := AdjustRouteMetric(netip.MustParsePrefix("10.20.0.0/16"), netip.MustParseAddr("10.0.0.1"), 7, -15); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.rows[2].Metric; got != 0 {
+		t.Fatalf("expected the metric to be clamped to 0, got %d", got)
+	}
+	if err := AdjustRouteMetric(netip.MustParsePrefix("10.99.0.0/16"), netip.MustParseAddr("10.0.0.1"), 7, 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	changed, partialErrs, err := SetMetricForRoutes(10, WithInterfaceIndex(7))
+	if err != nil || len(partialErrs) > 0 || changed != 1 {
+		t.Fatalf("expected one route to change, got %d, %v, %v", changed, partialErrs, err)
+	}
+	if f.rows[2].Metric != 10 || f.rows[3].Metric != 10 {
+		t.Fatalf("expected both routes at metric 10, got %d and %d", f.rows[2].Metric, f.rows[3].Metric)
+	}
+}
+
+func TestWatchInterfaces(t *testing.T) {
+	f := newFakeProvider(t)
+	useProvider(t, f)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := WatchInterfaces(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	watcher := f.watcher
+	go watcher(winapi.MibParameterNotification, &winapi.MibIPInterfaceRow{
+		Family: winapi.AF_INET, InterfaceLUID: ethernetLUID, InterfaceIndex: 5, Metric: 35, Connected: true,
+	})
+	event := <-events
+	if event.Type != InterfaceChanged || event.Index != 5 || event.Metric != 35 || !event.Connected {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	if f.watcher != nil {
+		t.Fatal("expected the callback to be unregistered")
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	useProvider(t, newFakeProvider(t))
+	table, err := NewRouteTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	table.SetAnnotationPath(filepath.Join(t.TempDir(), "notes.json"))
+
+	routes, err := table.GetRoutes(WithInterfaceIndex(7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := table.Annotate(routes[1], "备用线路"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	notes, err := table.Annotations(routes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"", "备用线路"}; !slices.Equal(notes, want) {
+		t.Fatalf("expected %q, got %q", want, notes)
+	}
+
+	if err := table.Annotate(routes[1], ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if notes, _ := table.Annotations(routes); !slices.Equal(notes, []string{"", ""}) {
+		t.Fatalf("expected the note to be removed, got %q", notes)
+	}
+}
+
+func TestAddRouteInvalidParameterCause(t *testing.T) {
+	f := newFakeProvider(t)
+	f.createErr = winapi.ERROR_INVALID_PARAMETER
+	useProvider(t, f)
+
+	err := AddRoute(netip.MustParsePrefix("2001:db8::/32"), netip.Addr{}, 5, 0)
+	if !errors.Is(err, winapi.ERROR_INVALID_PARAMETER) || !strings.Contains(err.Error(), "IPv6 is not enabled on interface 5") {
+		t.Fatalf("expected the disabled family to be reported, got %v", err)
+	}
+
+	err = AddRoute(netip.MustParsePrefix("2001:db8::/32"), netip.MustParseAddr("192.168.1.1"), 5, 0)
+	if err == nil || errors.Is(err, winapi.ERROR_INVALID_PARAMETER) || len(f.created) != 0 {
+		t.Fatalf("expected a family mismatch to be rejected before the system call, got %v", err)
+	}
+}
+
+func TestUnknownIPInterfaceInfo(t *testing.T) {
+	f := newFakeProvider(t)
+	f.createErr = winapi.ERROR_INVALID_PARAMETER
+	for _, iface := range f.ifaces {
+		iface.metricV4, iface.automaticMetricV4, iface.ipv4Enabled = 0, false, false
+		iface.metricV6, iface.automaticMetricV6, iface.ipv6Enabled = 0, false, false
+		iface.ipInfoUnknown = true
+	}
+	useProvider(t, f)
+
+	report, err := GetInterfaceReport("5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Metric != 0 || report.AutomaticMetric || len(report.Routes) == 0 {
+		t.Fatalf("expected an unknown metric and the interface routes, got %+v", report)
+	}
+
+	err = AddRoute(netip.MustParsePrefix("2001:db8::/32"), netip.Addr{}, 5, 0)
+	if !errors.Is(err, winapi.ERROR_INVALID_PARAMETER) || strings.Contains(err.Error(), "not enabled") {
+		t.Fatalf("expected an unknown family not to be reported as disabled, got %v", err)
+	}
+}
+
+func TestPlanRoutes(t *testing.T) {
+	f := newFakeProvider(t)
+	f.rows = append(f.rows, fakeRow(t, chineseLUID, "10.50.0.0/16", "10.0.0.1", 10))
+	useProvider(t, f)
+
+	spec := func(destination, nextHop string, metric uint32) RouteSpec {
+		return RouteSpec{
+			Destination:    netip.MustParsePrefix(destination),
+			NextHop:        netip.MustParseAddr(nextHop),
+			InterfaceIndex: 7,
+			Metric:         metric,
+		}
+	}
+	desired := []RouteSpec{
+		spec("10.20.0.0/16", "10.0.0.1", 10),
+		spec("10.30.0.5/16", "172.16.0.1", 20),
+		spec("10.40.0.0/16", "10.0.0.1", 10),
+		spec("10.40.0.0/16", "10.0.0.1", 10),
+	}
+	toAdd, toDelete, toUpdate, err := PlanRoutes(desired)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	specDestinations := func(specs []RouteSpec) []string {
+		dests := []string{}
+		for _, spec := range specs {
+			dests = append(dests, spec.Destination.String())
+		}
+		return dests
+	}
+	if got := specDestinations(toAdd); !slices.Equal(got, []string{"10.40.0.0/16"}) {
+		t.Fatalf("unexpected routes to add: %v", got)
+	}
+	if got := specDestinations(toUpdate); !slices.Equal(got, []string{"10.30.0.0/16"}) {
+		t.Fatalf("unexpected routes to update: %v", got)
+	}
+	if got := specDestinations(toDelete); !slices.Equal(got, []string{"10.50.0.0/16"}) {
+		t.Fatalf("unexpected routes to delete: %v", got)
+	}
+
+	// 被 scope 排除的现有路由不参与比较
+	_, toDelete, _, err = PlanRoutes(desired, WithMetric(20))
+	if err != nil || len(toDelete) != 0 {
+		t.Fatalf("expected nothing to delete in scope, got %v, %v", toDelete, err)
+	}
+}
+
+func TestExportImportRoutes(t *testing.T) {
+	source := newFakeProvider(t)
+	useProvider(t, source)
+
+	var buf bytes.Buffer
+	if err := ExportRoutes(&buf); err != nil {
+		t.Fatalf("ExportRoutes: %v", err)
+	}
+
+	// 恢复到空路由表：除系统路由（127.0.0.0/8）外的所有路由都会被添加
+	target := newFakeProvider(t)
+	target.rows = nil
+	useProvider(t, target)
+	added, partialErrs, err := ImportRoutes(bytes.NewReader(buf.Bytes()))
+	if err != nil || len(partialErrs) > 0 {
+		t.Fatalf("ImportRoutes: %v, %v", err, partialErrs)
+	}
+	var restored []string
+	for _, row := range target.created {
+		restored = append(restored, row.DestinationPrefix.Prefix().String())
+	}
+	want := []string{"0.0.0.0/0", "192.168.1.0/24", "10.20.0.0/16", "10.30.0.0/16", "203.0.113.0/24"}
+	if added != len(want) || !slices.Equal(restored, want) {
+		t.Fatalf("expected %v restored, got %d: %v", want, added, restored)
+	}
+
+	// 恢复到原路由表：所有路由都已存在，不会重复添加
+	useProvider(t, source)
+	added, partialErrs, err = ImportRoutes(bytes.NewReader(buf.Bytes()))
+	if err != nil || len(partialErrs) > 0 || added != 0 || len(source.created) != 0 {
+		t.Fatalf("expected nothing to restore, got %d added, %v, %v", added, partialErrs, err)
+	}
+}
+
+func TestImportRoutesLinkLocalNextHop(t *testing.T) {
+	f := newFakeProvider(t)
+	f.rows = append(f.rows, fakeRow(t, ethernetLUID, "2001:db8:1::/48", "fe80::1%5", 10))
+	useProvider(t, f)
+
+	var buf bytes.Buffer
+	if err := ExportRoutes(&buf); err != nil {
+		t.Fatalf("ExportRoutes: %v", err)
+	}
+	// 路由已经存在，下一跳的 zone 不应使其被当作缺失的路由重新添加
+	added, partialErrs, err := ImportRoutes(bytes.NewReader(buf.Bytes()))
+	if err != nil || len(partialErrs) > 0 || added != 0 || len(f.created) != 0 {
+		t.Fatalf("expected nothing to restore, got %d added, %v, %v", added, partialErrs, err)
+	}
+}
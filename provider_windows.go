@@ -0,0 +1,238 @@
+//go:build windows
+
+package winroute
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"syscall"
+	"unsafe"
+
+	"github.com/bnkrr/winroute/internal/linkspeed"
+	"github.com/bnkrr/winroute/internal/persistroute"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+	"golang.zx2c4.com/wireguard/windows/tunnel/winipcfg"
+)
+
+// provider 是本包使用的 routeProvider，测试可以将其替换为伪实现。
+var provider routeProvider = winipcfgProvider{}
+
+// winipcfgProvider 通过 winipcfg 调用真实的系统 API。
+type winipcfgProvider struct{}
+
+func (winipcfgProvider) interfaces(family winipcfg.AddressFamily) ([]*Interface, error) {
+	adapters, err := winipcfg.GetAdaptersAddresses(family, windows.GAA_FLAG_INCLUDE_PREFIX|windows.GAA_FLAG_INCLUDE_GATEWAYS)
+	logSyscall("GetAdaptersAddresses", err, "family", family, "adapters", len(adapters))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get adapters addresses: %w", err)
+	}
+
+	ifaces := make([]*Interface, 0, len(adapters))
+	byLUID := make(map[winipcfg.LUID]*Interface, len(adapters))
+	for _, adapter := range adapters {
+		// adapter.FriendlyName() 通常就是我们需要的接口 "别名" (Alias)，
+		// 例如 "以太网" 或 "Wi-Fi"。直接使用它可以简化代码。
+		iface := &Interface{
+			Index:       adapter.IfIndex,
+			LUID:        adapter.LUID,
+			Alias:       adapter.FriendlyName(),
+			Description: adapter.Description(),
+			Addresses:   unicastAddresses(adapter),
+			OperStatus:  adapter.OperStatus,
+			IfType:      adapter.IfType,
+			// 系统用全 1 表示速度未知，统一为 0
+			TransmitSpeed: knownSpeed(adapter.TransmitLinkSpeed),
+			ReceiveSpeed:  knownSpeed(adapter.ReceiveLinkSpeed),
+			Gateways:      gatewayAddresses(adapter),
+			DNSServers:    dnsServerAddresses(adapter),
+		}
+		ifaces = append(ifaces, iface)
+		byLUID[iface.LUID] = iface
+	}
+
+	// IP 接口表按地址族提供接口 metric 及其是否自动计算等信息。
+	// 读取失败时仍返回接口列表，只把这部分信息标记为未知，不让整个查询失败。
+	ipInterfaces, err := winipcfg.GetIPInterfaceTable(family)
+	logSyscall("GetIPInterfaceTable", err, "family", family, "interfaces", len(ipInterfaces))
+	if err != nil {
+		for _, iface := range ifaces {
+			iface.ipInfoUnknown = true
+		}
+		return ifaces, nil
+	}
+	for i := range ipInterfaces {
+		row := &ipInterfaces[i]
+		iface, ok := byLUID[row.InterfaceLUID]
+		if !ok {
+			continue
+		}
+		switch row.Family {
+		case windows.AF_INET:
+			iface.metricV4 = row.Metric
+			iface.automaticMetricV4 = row.UseAutomaticMetric
+			iface.ipv4Enabled = true
+		case windows.AF_INET6:
+			iface.metricV6 = row.Metric
+			iface.automaticMetricV6 = row.UseAutomaticMetric
+			iface.ipv6Enabled = true
+		}
+	}
+	return ifaces, nil
+}
+
+// knownSpeed 把系统表示未知的链路速度转换为 0。
+func knownSpeed(bps uint64) uint64 {
+	if bps == linkspeed.Unknown {
+		return 0
+	}
+	return bps
+}
+
+// unicastAddresses 收集适配器上的单播地址，并附带其链路前缀长度。
+func unicastAddresses(adapter *winipcfg.IPAdapterAddresses) []netip.Prefix {
+	var addresses []netip.Prefix
+	for ua := adapter.FirstUnicastAddress; ua != nil; ua = ua.Next {
+		addr, ok := socketAddr(&ua.Address)
+		if !ok {
+			continue
+		}
+		// 使用 PrefixFrom 而不是 addr.Prefix，以保留地址本身的主机位。
+		prefix := netip.PrefixFrom(addr, int(ua.OnLinkPrefixLength))
+		if !prefix.IsValid() {
+			continue
+		}
+		addresses = append(addresses, prefix)
+	}
+	return addresses
+}
+
+// gatewayAddresses 收集适配器上配置的默认网关地址。
+func gatewayAddresses(adapter *winipcfg.IPAdapterAddresses) []netip.Addr {
+	var gateways []netip.Addr
+	for ga := adapter.FirstGatewayAddress; ga != nil; ga = ga.Next {
+		if addr, ok := socketAddr(&ga.Address); ok {
+			gateways = append(gateways, addr)
+		}
+	}
+	return gateways
+}
+
+// dnsServerAddresses 收集适配器上配置的 DNS 服务器地址。
+func dnsServerAddresses(adapter *winipcfg.IPAdapterAddresses) []netip.Addr {
+	var servers []netip.Addr
+	for ds := adapter.FirstDNSServerAddress; ds != nil; ds = ds.Next {
+		if addr, ok := socketAddr(&ds.Address); ok {
+			servers = append(servers, addr)
+		}
+	}
+	return servers
+}
+
+// socketAddr 将 windows.SocketAddress 转换为 netip.Addr。
+func socketAddr(sa *windows.SocketAddress) (netip.Addr, bool) {
+	addr, ok := netip.AddrFromSlice(sa.IP())
+	if !ok {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+func (winipcfgProvider) routeTable(family winipcfg.AddressFamily) ([]winipcfg.MibIPforwardRow2, error) {
+	rows, err := winipcfg.GetIPForwardTable2(family)
+	logSyscall("GetIPForwardTable2", err, "family", family, "routes", len(rows))
+	return rows, err
+}
+
+func (winipcfgProvider) createRoute(row *winipcfg.MibIPforwardRow2) error {
+	return row.Create()
+}
+
+func (winipcfgProvider) deleteRoute(luid winipcfg.LUID, destination netip.Prefix, nextHop netip.Addr) error {
+	return luid.DeleteRoute(destination, nextHop)
+}
+
+func (winipcfgProvider) route(luid winipcfg.LUID, destination netip.Prefix, nextHop netip.Addr) (*winipcfg.MibIPforwardRow2, error) {
+	return luid.Route(destination, nextHop)
+}
+
+func (winipcfgProvider) setRoute(row *winipcfg.MibIPforwardRow2) error {
+	return row.Set()
+}
+
+func (winipcfgProvider) ipInterface(luid winipcfg.LUID, family winipcfg.AddressFamily) (*winipcfg.MibIPInterfaceRow, error) {
+	return luid.IPInterface(family)
+}
+
+func (winipcfgProvider) setIPInterface(row *winipcfg.MibIPInterfaceRow) error {
+	return row.Set()
+}
+
+func (winipcfgProvider) watchInterfaces(callback func(winipcfg.MibNotificationType, *winipcfg.MibIPInterfaceRow)) (func() error, error) {
+	cb, err := winipcfg.RegisterInterfaceChangeCallback(callback)
+	if err != nil {
+		return nil, err
+	}
+	return cb.Unregister, nil
+}
+
+func (winipcfgProvider) luidFromIndex(index uint32) (winipcfg.LUID, error) {
+	luid, err := winipcfg.LUIDFromIndex(index)
+	logSyscall("LUIDFromIndex", err, "index", index)
+	return luid, err
+}
+
+func (winipcfgProvider) indexFromLUID(luid winipcfg.LUID) (uint32, error) {
+	row, err := luid.Interface()
+	logSyscall("GetIfEntry2", err, "luid", luid)
+	if err != nil {
+		return 0, err
+	}
+	return row.InterfaceIndex, nil
+}
+
+// winipcfg 没有封装 GetBestRoute2，直接从 iphlpapi.dll 调用。
+var procGetBestRoute2 = windows.NewLazySystemDLL("iphlpapi.dll").NewProc("GetBestRoute2")
+
+func (winipcfgProvider) bestRoute(destination netip.Addr) (*winipcfg.MibIPforwardRow2, netip.Addr, error) {
+	var dest, source winipcfg.RawSockaddrInet
+	if err := dest.SetAddr(destination); err != nil {
+		return nil, netip.Addr{}, err
+	}
+	row := &winipcfg.MibIPforwardRow2{}
+	// GetBestRoute2(InterfaceLuid, InterfaceIndex, SourceAddress, DestinationAddress,
+	//               AddressSortOptions, BestRoute, BestSourceAddress)
+	r0, _, _ := syscall.SyscallN(procGetBestRoute2.Addr(),
+		0, 0, 0,
+		uintptr(unsafe.Pointer(&dest)),
+		0,
+		uintptr(unsafe.Pointer(row)),
+		uintptr(unsafe.Pointer(&source)),
+	)
+	var err error
+	if r0 != 0 {
+		err = windows.Errno(r0)
+	}
+	logSyscall("GetBestRoute2", err, "destination", destination)
+	if err != nil {
+		return nil, netip.Addr{}, err
+	}
+	return row, source.Addr(), nil
+}
+
+func (winipcfgProvider) persistentRoutes() ([]string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, persistroute.Key, registry.QUERY_VALUE)
+	logSyscall("RegOpenKeyEx", err, "key", persistroute.Key)
+	if errors.Is(err, windows.ERROR_FILE_NOT_FOUND) {
+		// 从未添加过持久路由
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+	names, err := key.ReadValueNames(-1)
+	logSyscall("RegEnumValue", err, "key", persistroute.Key, "routes", len(names))
+	return names, err
+}
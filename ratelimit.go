@@ -0,0 +1,38 @@
+package winroute
+
+import (
+	"time"
+
+	"github.com/bnkrr/winroute/internal/pace"
+)
+
+// RateLimitPolicy 限制批量操作发起系统调用的速率，由 RateLimit 创建。
+// 可以作为选项传给 AddRoutes、AddRoutesBatch、DeleteRoutes 和 DeleteRoutesBatch。
+type RateLimitPolicy struct {
+	perSecond int
+}
+
+// RateLimit 创建一个限速选项：批量操作每秒最多处理 perSecond 条路由，相邻两条路由的操作至少间隔
+// 1/perSecond 秒。perSecond 必须大于 0，否则批量操作返回错误。
+//
+// 限速按路由计算：与 WithRetry 一起使用时，同一条路由的重试只按 backoff 等待，不额外限速，
+// 下一条路由仍与上一条路由的操作开始时间保持间隔。
+//
+// 批量操作不接受 context，限速产生的等待无法取消，整个调用大约需要 路由数/perSecond 秒；
+// 需要随时停止的调用方可以把路由分成小批，在批次之间检查自己的 context。
+func RateLimit(perSecond int) RateLimitPolicy {
+	return RateLimitPolicy{perSecond: perSecond}
+}
+
+// withRateLimit 包装批量操作中对单条路由的操作 op，使相邻两次调用至少间隔 1/perSecond 秒。
+// 零值策略不限速。
+func withRateLimit[T any](p RateLimitPolicy, op func(T) error) func(T) error {
+	if p.perSecond <= 0 {
+		return op
+	}
+	pacer := pace.New(time.Second / time.Duration(p.perSecond))
+	return func(item T) error {
+		pacer.Wait()
+		return op(item)
+	}
+}
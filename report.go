@@ -0,0 +1,46 @@
+package winroute
+
+import (
+	"net/netip"
+	"slices"
+)
+
+// InterfaceReport 汇总一个接口的完整信息，由 GetInterfaceReport 返回。
+type InterfaceReport struct {
+	Interface *Interface
+	// Metric 是接口 metric，AutomaticMetric 表示它是否由系统自动计算。
+	// 取值规则与 GetInterfaceMetric 相同：启用了 IPv4 时为 IPv4 的设置，否则为 IPv6 的设置；
+	// 两个地址族都未启用（例如网卡未绑定 TCP/IP）或 IP 接口表不可用时均为零值。
+	Metric          uint32
+	AutomaticMetric bool
+	// Gateways 是接口上配置的默认网关（与 Interface.Gateways 相同）。
+	Gateways []netip.Addr
+	// Routes 是使用该接口作为出接口的所有路由，按路由表顺序排列。
+	Routes []*Route
+}
+
+// GetInterfaceReport 一次性返回 identifier（接口索引或别名，规则同 ResolveInterfaces）指定接口的
+// 接口信息、接口 metric、网关和经由它的路由，所有信息来自同一次查询，适合用于诊断。
+// 别名匹配多个接口时返回 ErrAmbiguousMatch，接口不存在时返回 ErrNotFound。
+func GetInterfaceReport(identifier string) (*InterfaceReport, error) {
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return nil, err
+	}
+	iface, err := cache.resolveInterface(identifier)
+	if err != nil {
+		return nil, err
+	}
+	metric, automatic := iface.interfaceMetric()
+	routes, err := getRoutes(cache, []FilterOption{WithInterfaceIndex(iface.Index)})
+	if err != nil {
+		return nil, err
+	}
+	return &InterfaceReport{
+		Interface:       iface,
+		Metric:          metric,
+		AutomaticMetric: automatic,
+		Gateways:        slices.Clone(iface.Gateways),
+		Routes:          routes,
+	}, nil
+}
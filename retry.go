@@ -0,0 +1,55 @@
+package winroute
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bnkrr/winroute/internal/retry"
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// transientErrors 是被视为暂时性、值得重试的 Windows 错误码，
+// 通常出现在适配器初始化或系统繁忙期间：
+//   - ERROR_NOT_READY (21)
+//   - ERROR_NETWORK_BUSY (54)
+//   - ERROR_BUSY (170)
+//   - ERROR_RETRY (1237)
+//   - ERROR_TIMEOUT (1460)
+//   - ERROR_DEVICE_NOT_AVAILABLE (4319)
+//
+// ERROR_ACCESS_DENIED、ERROR_OBJECT_ALREADY_EXISTS、ERROR_NOT_FOUND 等永久性错误不会重试。
+var transientErrors = []winapi.Errno{
+	winapi.ERROR_NOT_READY,
+	winapi.ERROR_NETWORK_BUSY,
+	winapi.ERROR_BUSY,
+	winapi.ERROR_RETRY,
+	winapi.ERROR_TIMEOUT,
+	winapi.ERROR_DEVICE_NOT_AVAILABLE,
+}
+
+// RetryPolicy 描述增删路由时对暂时性错误的重试策略，由 WithRetry 创建。
+// 可以作为选项传给 AddRoute、AddRouteSpec、AddRoutes 和 DeleteRoutes。
+type RetryPolicy struct {
+	attempts int
+	backoff  time.Duration
+}
+
+// WithRetry 创建一个重试策略：每个系统调用最多执行 attempts 次，两次尝试之间等待 backoff。
+// 只有 transientErrors 中列出的暂时性错误会触发重试。
+func WithRetry(attempts int, backoff time.Duration) RetryPolicy {
+	return RetryPolicy{attempts: attempts, backoff: backoff}
+}
+
+// do 按重试策略执行 fn。零值策略只执行一次。
+func (p RetryPolicy) do(fn func() error) error {
+	return retry.Do(p.attempts, p.backoff, isTransientError, fn)
+}
+
+func isTransientError(err error) bool {
+	for _, code := range transientErrors {
+		if errors.Is(err, code) {
+			return true
+		}
+	}
+	return false
+}
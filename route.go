@@ -1,28 +1,46 @@
-//go:build windows
-
 // Package winroute 提供了一个现代化、用户友好的接口来操作 Windows 路由表。
 // 它建立在 wireguard/winipcfg 之上，封装了底层的复杂性，
 // 提供了信息聚合和便捷的操作功能。
 package winroute
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/netip"
+	"path"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/bnkrr/winroute/internal/addrrange"
 	"github.com/bnkrr/winroute/internal/aliascheck"
+	"github.com/bnkrr/winroute/internal/aliasfold"
+	"github.com/bnkrr/winroute/internal/bestmatch"
+	"github.com/bnkrr/winroute/internal/bounded"
+	"github.com/bnkrr/winroute/internal/lifetime"
+	"github.com/bnkrr/winroute/internal/persistroute"
+	"github.com/bnkrr/winroute/internal/routecheck"
 	"github.com/bnkrr/winroute/internal/routeops"
-	"golang.org/x/sys/windows"
-	"golang.zx2c4.com/wireguard/windows/tunnel/winipcfg"
+	"github.com/bnkrr/winroute/internal/scope"
+	"github.com/bnkrr/winroute/internal/srcaddr"
+	"github.com/bnkrr/winroute/internal/unmap"
+	"github.com/bnkrr/winroute/internal/winapi"
 )
 
 // ErrNotFound 表示未找到指定的路由或接口。
 var ErrNotFound = errors.New("not found")
 
+// ErrAccessDenied 表示操作因权限不足而失败，修改路由表通常需要管理员权限。
+var ErrAccessDenied = errors.New("access denied; administrator privileges are required")
+
 // ErrAmbiguousMatch 表示过滤器条件匹配了多个路由，无法确定要操作的单个目标。
 var ErrAmbiguousMatch = errors.New("filter criteria matched multiple routes")
 
+// ErrInterfaceDown 表示传入 RequireInterfaceUp 时，路由的接口未处于运行状态。
+var ErrInterfaceDown = errors.New("interface is not up")
+
 // ---- GetRoutes: 查询路由 ----
 
 // FilterOption defines route filtering plus any pre-checks needed before route enumeration.
@@ -48,20 +66,51 @@ func (f filterOption) validate(cache *interfaceCache) error {
 }
 
 // WithDestinationPrefix 创建一个过滤器，仅保留目标网段完全匹配的路由。
+// IPv4 映射的 IPv6 前缀（如 ::ffff:10.0.0.0/104）按对应的 IPv4 前缀（10.0.0.0/8）匹配。
 func WithDestinationPrefix(prefix netip.Prefix) FilterOption {
+	prefix = unmap.Prefix(prefix)
 	return filterOption{matchFn: func(r *Route) bool {
 		return r.Destination == prefix
 	}}
 }
 
+// WithDestinationPrefixes 创建一个过滤器，仅保留目标网段与 prefixes 中任意一个完全匹配的路由。
+// 与 DeleteRoutes 一起使用时，只需遍历一次路由表就能删除一组已知的网段。
+// 不传入任何前缀时不匹配任何路由。IPv4 映射的 IPv6 前缀的处理方式与 WithDestinationPrefix 相同。
+func WithDestinationPrefixes(prefixes ...netip.Prefix) FilterOption {
+	set := make(map[netip.Prefix]struct{}, len(prefixes))
+	for _, prefix := range prefixes {
+		set[unmap.Prefix(prefix)] = struct{}{}
+	}
+	return filterOption{matchFn: func(r *Route) bool {
+		_, ok := set[r.Destination]
+		return ok
+	}}
+}
+
 func validateUniqueAlias(cache *interfaceCache, alias string) error {
-	count := cache.aliasCount[strings.ToLower(alias)]
+	count := cache.aliasCount[aliasfold.Key(alias)]
 	if err := aliascheck.ValidateUniqueAlias(alias, count); err != nil {
 		return fmt.Errorf("%w: %v", ErrAmbiguousMatch, err)
 	}
 	return nil
 }
 
+// WithDestinationInRange 创建一个过滤器，仅保留目标前缀完全落在闭区间 [start, end] 内的路由，
+// 例如 10.0.0.0 到 10.0.255.255 匹配 10.0.4.0/24，但不匹配 10.0.0.0/8。
+// start 和 end 必须属于同一地址族且 start 不大于 end，否则查询返回错误；另一地址族的路由不会匹配。
+func WithDestinationInRange(start, end netip.Addr) FilterOption {
+	r, err := addrrange.New(start, end)
+	return filterOption{
+		matchFn: func(route *Route) bool {
+			return err == nil && r.ContainsPrefix(route.Destination)
+		},
+		validateFn: func(*interfaceCache) error {
+			return err
+		},
+	}
+}
+
 // WithInterfaceIndex 创建一个过滤器，仅保留通过指定接口索引的路由。
 func WithInterfaceIndex(index uint32) FilterOption {
 	return filterOption{matchFn: func(r *Route) bool {
@@ -69,18 +118,249 @@ func WithInterfaceIndex(index uint32) FilterOption {
 	}}
 }
 
+// validateAlias 检查 alias 恰好对应一个接口：没有接口使用该别名时返回 ErrNotFound，
+// 多个接口使用该别名时返回 ErrAmbiguousMatch。
+func validateAlias(cache *interfaceCache, alias string) error {
+	if cache.aliasCount[aliasfold.Key(alias)] == 0 {
+		return fmt.Errorf("interface alias %q: %w", alias, ErrNotFound)
+	}
+	return validateUniqueAlias(cache, alias)
+}
+
 // WithInterfaceAlias 创建一个过滤器，仅保留通过指定接口别名（不区分大小写）的路由。
+// 没有接口使用该别名时查询返回 ErrNotFound，以便区分“接口上没有路由”和“接口不存在”；
+// 多个接口使用该别名时返回 ErrAmbiguousMatch。
 func WithInterfaceAlias(alias string) FilterOption {
 	return filterOption{
 		matchFn: func(r *Route) bool {
 			return strings.EqualFold(r.Interface.Alias, alias)
 		},
 		validateFn: func(cache *interfaceCache) error {
-			return validateUniqueAlias(cache, alias)
+			return validateAlias(cache, alias)
 		},
 	}
 }
 
+// WithInterfaceAliasPattern 创建一个过滤器，仅保留接口别名与 glob 模式匹配（不区分大小写）的路由，
+// 例如 "vEthernet *" 同时匹配 "vEthernet (WSL)" 和 "vEthernet (Default Switch)"。
+// 模式语法与 path.Match 相同（*、?、[...]，\ 用于转义）；模式无效时查询返回错误。
+// 与 WithInterfaceAlias 不同，匹配多个接口是预期行为，不会返回 ErrAmbiguousMatch；
+// 但模式不匹配任何接口时同样返回 ErrNotFound。
+func WithInterfaceAliasPattern(pattern string) FilterOption {
+	pattern = aliasfold.Key(pattern)
+	return filterOption{
+		matchFn: func(r *Route) bool {
+			ok, _ := path.Match(pattern, aliasfold.Key(r.Interface.Alias))
+			return ok
+		},
+		validateFn: func(cache *interfaceCache) error {
+			_, err := cache.interfacesMatching(pattern)
+			return err
+		},
+	}
+}
+
+// WithInterfaceAliasPrefix 创建一个过滤器，仅保留接口别名以 prefix 开头（不区分大小写）的路由，
+// 例如 "vEthernet" 匹配所有 Hyper-V 虚拟网卡。它是 WithInterfaceAliasPattern(prefix + "*") 的简化形式，
+// 但 prefix 中的 *、? 等字符按字面匹配。没有接口匹配时查询返回 ErrNotFound。
+func WithInterfaceAliasPrefix(prefix string) FilterOption {
+	prefix = aliasfold.Key(prefix)
+	return filterOption{
+		matchFn: func(r *Route) bool {
+			return strings.HasPrefix(aliasfold.Key(r.Interface.Alias), prefix)
+		},
+		validateFn: func(cache *interfaceCache) error {
+			for _, iface := range cache.all {
+				if strings.HasPrefix(aliasfold.Key(iface.Alias), prefix) {
+					return nil
+				}
+			}
+			return fmt.Errorf("no interface alias starts with %q: %w", prefix, ErrNotFound)
+		},
+	}
+}
+
+// WithInterfaceIndexIn 创建一个过滤器，仅保留接口索引属于 indices 之一的路由。
+func WithInterfaceIndexIn(indices ...uint32) FilterOption {
+	set := make(map[uint32]struct{}, len(indices))
+	for _, index := range indices {
+		set[index] = struct{}{}
+	}
+	return filterOption{matchFn: func(r *Route) bool {
+		_, ok := set[r.Interface.Index]
+		return ok
+	}}
+}
+
+// WithInterfaceAliasIn 创建一个过滤器，仅保留接口别名（不区分大小写）属于 aliases 之一的路由。
+// 与 WithInterfaceAlias 一样，任何一个别名不对应任何接口时返回 ErrNotFound，对应多个接口时返回 ErrAmbiguousMatch。
+func WithInterfaceAliasIn(aliases ...string) FilterOption {
+	set := make(map[string]struct{}, len(aliases))
+	for _, alias := range aliases {
+		set[aliasfold.Key(alias)] = struct{}{}
+	}
+	return filterOption{
+		matchFn: func(r *Route) bool {
+			_, ok := set[aliasfold.Key(r.Interface.Alias)]
+			return ok
+		},
+		validateFn: func(cache *interfaceCache) error {
+			for _, alias := range aliases {
+				if err := validateAlias(cache, alias); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// WithInterfaceDescription 创建一个过滤器，仅保留接口描述包含 substr（不区分大小写）的路由。
+// 当多个接口使用相同别名时，可以用接口描述（例如 "Realtek PCIe GbE Family Controller"）区分它们。
+func WithInterfaceDescription(substr string) FilterOption {
+	substr = aliasfold.Key(substr)
+	return filterOption{matchFn: func(r *Route) bool {
+		return strings.Contains(aliasfold.Key(r.Interface.Description), substr)
+	}}
+}
+
+// WithInterfaceType 创建一个过滤器，仅保留接口类型（Interface.IfType）为 ifType 的路由，
+// 例如传入 winipcfg.IfTypeEthernetCSMACD 只选择有线网卡上的路由。
+func WithInterfaceType(ifType winapi.IfType) FilterOption {
+	return filterOption{matchFn: func(r *Route) bool {
+		return r.Interface.IfType == ifType
+	}}
+}
+
+// WithSourceAddress 创建一个过滤器，仅保留推断出的源地址（Route.PreferredSource）等于 addr 的路由。
+// IPv4 映射的 IPv6 地址按对应的 IPv4 地址匹配。
+func WithSourceAddress(addr netip.Addr) FilterOption {
+	addr = addr.WithZone("").Unmap()
+	return filterOption{matchFn: func(r *Route) bool {
+		return r.PreferredSource == addr
+	}}
+}
+
+// WithNextHop 创建一个过滤器，仅保留下一跳等于 addr 的路由，例如找出经由某个网关的全部路由。
+// 比较时忽略 zone，IPv4 映射的 IPv6 地址按对应的 IPv4 地址匹配；
+// 传入 0.0.0.0 或 :: 可以选出对应地址族的直连（on-link）路由。
+func WithNextHop(addr netip.Addr) FilterOption {
+	addr = addr.WithZone("").Unmap()
+	return filterOption{matchFn: func(r *Route) bool {
+		return r.NextHop.WithZone("") == addr
+	}}
+}
+
+// WithGatewayRoutes 创建一个过滤器，仅保留经由网关的路由，即下一跳是有效且非未指定地址的路由，
+// 排除直连（on-link）路由以及本机地址、环回等下一跳为 0.0.0.0 或 :: 的路由。
+// 这通常就是管理员所说的“网关路由”；与 WithNextHop(netip.IPv4Unspecified()) 正好相反。
+func WithGatewayRoutes() FilterOption {
+	return filterOption{matchFn: func(r *Route) bool {
+		return routecheck.HasGateway(r.NextHop)
+	}}
+}
+
+// WithPrefixLength 创建一个过滤器，仅保留目标前缀长度等于 bits 的路由。
+// 例如 bits 为 32 或 128 时匹配主机路由，为 0 时匹配默认路由。
+func WithPrefixLength(bits int) FilterOption {
+	return filterOption{matchFn: func(r *Route) bool {
+		return r.Destination.Bits() == bits
+	}}
+}
+
+// WithoutSystemRoutes 创建一个过滤器，排除所有系统路由（见 Route.IsSystemRoute）。
+// 清理路由时使用它可以避免误删系统关键的路由条目。
+func WithoutSystemRoutes() FilterOption {
+	return filterOption{matchFn: func(r *Route) bool {
+		return !r.IsSystemRoute()
+	}}
+}
+
+// Without 创建一个过滤器，排除与给定路由相同（按 Route.Equal 判断）的路由。
+// 适合在已经持有具体 Route（例如刚添加的路由）时把它们从结果中剔除。nil 路由会被忽略。
+func Without(routes ...*Route) FilterOption {
+	excluded := make(map[equalKey]struct{}, len(routes))
+	for _, route := range routes {
+		if route != nil {
+			excluded[equalKeyOf(route)] = struct{}{}
+		}
+	}
+	return filterOption{matchFn: func(r *Route) bool {
+		_, ok := excluded[equalKeyOf(r)]
+		return !ok
+	}}
+}
+
+// equalKey 包含 Route.Equal 比较的全部字段，两条路由 Equal 当且仅当它们的 equalKey 相同。
+type equalKey struct {
+	identity routeIdentity
+	metric   uint32
+}
+
+func equalKeyOf(r *Route) equalKey {
+	return equalKey{identityOf(r), r.Metric}
+}
+
+// WithNextHopReachable 创建一个过滤器，仅保留下一跳在出接口上直接可达的路由：
+// 直连路由（下一跳未指定），或下一跳落在接口某个单播地址的链路前缀内。
+// 被排除的路由的网关在该接口上不可达，通常意味着配置错误；ValidateRoutes 会把它们报告为 IssueNextHopUnreachable。
+func WithNextHopReachable() FilterOption {
+	return filterOption{matchFn: func(r *Route) bool {
+		return !routecheck.HasGateway(r.NextHop) || routecheck.OnLink(r.NextHop, r.Interface.Addresses)
+	}}
+}
+
+// WithLinkLocal 创建一个过滤器，仅保留目标为链路本地地址的路由，见 Route.IsLinkLocal。
+func WithLinkLocal() FilterOption {
+	return filterOption{matchFn: func(r *Route) bool {
+		return r.IsLinkLocal()
+	}}
+}
+
+// WithUniqueLocal 创建一个过滤器，仅保留目标为 IPv6 唯一本地地址的路由，见 Route.IsUniqueLocal。
+func WithUniqueLocal() FilterOption {
+	return filterOption{matchFn: func(r *Route) bool {
+		return r.IsUniqueLocal()
+	}}
+}
+
+// familyFilter 按地址族筛选路由，并让查询只获取该地址族的系统路由表。
+type familyFilter struct {
+	family winapi.AddressFamily
+}
+
+func (f familyFilter) match(r *Route) bool {
+	return r.Destination.Addr().Is4() == (f.family == winapi.AF_INET)
+}
+
+func (f familyFilter) validate(*interfaceCache) error {
+	if f.family != winapi.AF_INET && f.family != winapi.AF_INET6 {
+		return fmt.Errorf("unsupported address family %d: must be AF_INET or AF_INET6", f.family)
+	}
+	return nil
+}
+
+func (f familyFilter) applyQuery(p *queryParameters) {
+	// 多个地址族过滤器相互矛盾时结果必然为空，只取第一个即可
+	if p.family == winapi.AF_UNSPEC {
+		p.family = f.family
+	}
+}
+
+// WithAddressFamily 创建一个过滤器，仅保留指定地址族（windows.AF_INET 或 windows.AF_INET6）的路由。
+// 与其他过滤器不同，它会让查询直接向系统请求该地址族的路由表（GetIPForwardTable2），
+// 而不是获取全部路由后再筛选，因此在路由很多的主机上能减少系统调用返回的数据量。
+func WithAddressFamily(family winapi.AddressFamily) FilterOption {
+	return familyFilter{family: family}
+}
+
+// WithBlackhole 创建一个过滤器，仅保留黑洞路由。判定规则见 Route.IsBlackhole。
+func WithBlackhole() FilterOption {
+	return filterOption{matchFn: func(r *Route) bool {
+		return r.IsBlackhole()
+	}}
+}
+
 // WithMetric 创建一个过滤器，仅保留Metric等于指定值的路由。
 func WithMetric(metric uint32) FilterOption {
 	return filterOption{matchFn: func(r *Route) bool {
@@ -88,27 +368,306 @@ func WithMetric(metric uint32) FilterOption {
 	}}
 }
 
+// WithMinAge 创建一个过滤器，仅保留存在时间（Route.Age）不少于 d 的路由，
+// 可用于在清理时找出长期存在的旧路由。
+func WithMinAge(d time.Duration) FilterOption {
+	return filterOption{matchFn: func(r *Route) bool {
+		return r.Age >= d
+	}}
+}
+
+// WithMaxAge 创建一个过滤器，仅保留存在时间（Route.Age）不超过 d 的路由，
+// 可用于找出最近才添加的路由（例如某个程序刚刚写入的路由）。
+func WithMaxAge(d time.Duration) FilterOption {
+	return filterOption{matchFn: func(r *Route) bool {
+		return r.Age <= d
+	}}
+}
+
+// RouteStore 表示路由所在的存储。
+type RouteStore int
+
+const (
+	// StoreActive 表示当前生效的路由表，即 GetRoutes 默认读取的内容。
+	StoreActive RouteStore = iota
+	// StorePersistent 表示重启后会恢复的持久路由，即 route -p add 写入注册表
+	// （HKLM\SYSTEM\CurrentControlSet\Services\Tcpip\Parameters\PersistentRoutes）的 IPv4 路由。
+	StorePersistent
+)
+
+// String 返回存储的名称。
+func (s RouteStore) String() string {
+	switch s {
+	case StoreActive:
+		return "active"
+	case StorePersistent:
+		return "persistent"
+	default:
+		return fmt.Sprintf("RouteStore(%d)", int(s))
+	}
+}
+
+// storeOption 选择查询读取的路由存储。
+type storeOption struct {
+	store RouteStore
+}
+
+func (storeOption) match(*Route) bool { return true }
+
+func (o storeOption) validate(*interfaceCache) error {
+	if o.store != StoreActive && o.store != StorePersistent {
+		return fmt.Errorf("invalid route store %v", o.store)
+	}
+	return nil
+}
+
+func (o storeOption) applyQuery(p *queryParameters) {
+	p.store = o.store
+}
+
+// WithStore 创建一个查询选项，选择查询读取的路由存储，用于区分“重启后会恢复的路由”和“只在当前生效的路由”。
+// 默认读取 StoreActive。
+//
+// StorePersistent 从注册表读取持久路由。注册表只记录目标、掩码、网关和 metric，
+// 因此出接口按网关所在的子网（链路直连路由按目标地址）在当前接口中确定，
+// 找不到这样的接口（例如对应的网卡已断开）的持久路由不会出现在结果中；
+// 结果的 Age 为 0，生存时间为无限。注册表中只有 IPv4 持久路由，查询 IPv6 时结果为空。
+//
+// WithStore 不缩小匹配范围，不算作 DeleteRoutes 的过滤器。
+// 修改路由的函数（DeleteRoutes、SetMetricForRoutes、PlanRoutes）只作用于当前路由表，传入 StorePersistent 时返回错误。
+func WithStore(store RouteStore) FilterOption {
+	return storeOption{store: store}
+}
+
+// requireActiveStore 在 filters 选择了 StorePersistent 时返回错误，供只能操作当前路由表的函数使用。
+func requireActiveStore(filters []FilterOption) error {
+	if extractQueryParameters(filters).store != StoreActive {
+		return errors.New("only routes in the active store can be modified; persistent routes are read-only")
+	}
+	return nil
+}
+
+// queryParameters 保存调整查询本身（而不是筛选路由）的选项。
+type queryParameters struct {
+	includeRawRow bool
+	deduplicate   bool
+	// store 是查询读取的路由存储，默认为 StoreActive
+	store RouteStore
+	// family 不为 AF_UNSPEC 时只从系统获取该地址族的路由表
+	family winapi.AddressFamily
+	// limit 大于 0 时在收集到 limit 条匹配的路由后停止遍历
+	limit int
+}
+
+// queryModifier 由需要调整查询行为的 FilterOption 实现。
+type queryModifier interface {
+	applyQuery(*queryParameters)
+}
+
+// queryOption 是不筛选路由、只调整查询行为的 FilterOption，它匹配所有路由。
+type queryOption struct {
+	apply func(*queryParameters)
+}
+
+func (queryOption) match(*Route) bool               { return true }
+func (queryOption) validate(*interfaceCache) error  { return nil }
+func (o queryOption) applyQuery(p *queryParameters) { o.apply(p) }
+
+// extractQueryParameters 从过滤器中收集查询选项。
+func extractQueryParameters(filters []FilterOption) queryParameters {
+	params := queryParameters{family: winapi.AF_UNSPEC}
+	for _, filter := range filters {
+		if q, ok := filter.(queryModifier); ok {
+			q.applyQuery(&params)
+		}
+	}
+	return params
+}
+
+// IncludeRawRow 创建一个查询选项，使返回的每条 Route 都附带系统返回的原始 MIB_IPFORWARD_ROW2，
+// 可通过 Route.Raw 访问，用于读取 Route 未公开的字段（例如 SitePrefixLength）。
+// 它不筛选路由，可以与其他过滤器一起传入 GetRoutes。
+func IncludeRawRow() FilterOption {
+	return queryOption{apply: func(p *queryParameters) {
+		p.includeRawRow = true
+	}}
+}
+
+// Deduplicate 创建一个查询选项，将相同（按 Route.Equal 判断）的路由合并为一条，
+// 只保留第一次出现的那条，结果保持系统返回的顺序。
+func Deduplicate() FilterOption {
+	return queryOption{apply: func(p *queryParameters) {
+		p.deduplicate = true
+	}}
+}
+
+// limitOption 限制查询返回的路由数量。
+type limitOption struct {
+	n int
+}
+
+func (limitOption) match(*Route) bool { return true }
+
+func (o limitOption) validate(*interfaceCache) error {
+	if o.n < 1 {
+		return fmt.Errorf("invalid limit %d: must be at least 1", o.n)
+	}
+	return nil
+}
+
+func (o limitOption) applyQuery(p *queryParameters) {
+	// 多个 Limit 同时出现时取最小值
+	if p.limit == 0 || o.n < p.limit {
+		p.limit = o.n
+	}
+}
+
+// Limit 创建一个查询选项，使查询在收集到 n 条匹配的路由后停止，结果按系统返回的顺序截断。
+// n 必须至少为 1。与 Deduplicate 一起使用时，按去重后的路由计数。
+func Limit(n int) FilterOption {
+	return limitOption{n: n}
+}
+
 // GetRoutes 获取系统路由表，并可选择性地应用一个或多个过滤器。
 func GetRoutes(filters ...FilterOption) ([]*Route, error) {
-	// 1. 构建接口缓存，以便后面快速查找接口信息
-	cache, err := newInterfaceCache()
+	cache, err := buildInterfaceCache()
 	if err != nil {
-		return nil, fmt.Errorf("failed to build interface cache: %w", err)
+		return nil, err
 	}
+	return getRoutes(cache, filters)
+}
+
+// getRoutes 使用调用方提供的接口缓存查询路由，批量操作借此在整个调用中只构建一次缓存。
+func getRoutes(cache *interfaceCache, filters []FilterOption) ([]*Route, error) {
+	routes := make([]*Route, 0)
+	err := scanRoutes(cache, filters, func(route *Route) bool {
+		r := *route
+		routes = append(routes, &r)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// GetRoutesGroupedByInterface 与 GetRoutes 相同，但按出接口索引对结果分组。
+// 每组中的路由保持系统返回的顺序。
+func GetRoutesGroupedByInterface(filters ...FilterOption) (map[uint32][]*Route, error) {
+	routes, err := GetRoutes(filters...)
+	if err != nil {
+		return nil, err
+	}
+	groups := make(map[uint32][]*Route)
+	for _, route := range routes {
+		groups[route.Interface.Index] = append(groups[route.Interface.Index], route)
+	}
+	return groups, nil
+}
+
+// GetRoutesContext 与 GetRoutes 相同，但在 ctx 结束时立即返回 ctx 的错误。
+//
+// 接口缓存的构建和路由表的获取在单独的 goroutine 中执行。注意：底层系统调用
+// （GetAdaptersAddresses、GetIPForwardTable2）无法被中断，超时或取消后它们可能仍在后台运行，
+// 直到系统调用自行返回，其结果会被丢弃。
+func GetRoutesContext(ctx context.Context, filters ...FilterOption) ([]*Route, error) {
+	routes, err := bounded.Call(ctx, func() ([]*Route, error) {
+		return GetRoutes(filters...)
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return nil, fmt.Errorf("route query did not complete: %w", err)
+	}
+	return routes, err
+}
+
+// GetRoutesTimeout 是 GetRoutesContext 的便捷封装：查询超过 timeout 时返回包装了
+// context.DeadlineExceeded 的错误。超时后底层系统调用可能仍在运行，见 GetRoutesContext。
+func GetRoutesTimeout(timeout time.Duration, filters ...FilterOption) ([]*Route, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return GetRoutesContext(ctx, filters...)
+}
+
+// CountRoutes 返回匹配过滤器的路由数量。
+// 它与 GetRoutes 使用相同的查询流程，但不会为每条路由分配并返回 *Route。
+func CountRoutes(filters ...FilterOption) (int, error) {
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = scanRoutes(cache, filters, func(*Route) bool {
+		count++
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// RangeRoutes 对每条匹配过滤器的路由调用 fn，fn 返回 false 时停止遍历。
+// 与 GetRoutes 不同，它不会构建包含全部结果的切片，适合在路由很多时流式处理或提前退出。
+// 每次传给 fn 的 *Route 都是独立的副本，可以在 fn 返回后继续使用。
+func RangeRoutes(fn func(*Route) bool, filters ...FilterOption) error {
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return err
+	}
+	return scanRoutes(cache, filters, func(route *Route) bool {
+		r := *route
+		return fn(&r)
+	})
+}
+
+// scanRoutes 是 GetRoutes 等查询函数的公共实现：它对每条匹配过滤器的路由调用 fn，
+// fn 返回 false 时停止遍历。
+// 传给 fn 的 *Route 在多次调用之间会被复用，fn 如需保留它必须自行复制。
+func scanRoutes(cache *interfaceCache, filters []FilterOption, fn func(*Route) bool) error {
+	return scanRoutesStats(cache, filters, nil, fn)
+}
+
+// scanRoutesStats 与 scanRoutes 相同；stats 不为 nil 时记录获取路由表和筛选的耗时及路由数量。
+func scanRoutesStats(cache *interfaceCache, filters []FilterOption, stats *RouteStats, fn func(*Route) bool) error {
+	// 1. 用接口缓存校验过滤器，缓存也用于后面快速查找接口信息
 	for _, filter := range filters {
 		if err := filter.validate(cache); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	// 2. 从 winipcfg 获取基础路由表
-	baseRoutes, err := winipcfg.GetIPForwardTable2(windows.AF_UNSPEC)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get base routing table: %w", err)
+	query := extractQueryParameters(filters)
+
+	// 2. 获取基础路由表
+	fetchStart := time.Now()
+	var baseRoutes []winapi.MibIPforwardRow2
+	var err error
+	if query.store == StorePersistent {
+		baseRoutes, err = persistentRouteTable(cache, query.family)
+		if err != nil {
+			return fmt.Errorf("failed to read persistent routes: %w", err)
+		}
+	} else {
+		baseRoutes, err = provider.routeTable(query.family)
+		if err != nil {
+			return fmt.Errorf("failed to get base routing table: %w", err)
+		}
+	}
+	if stats != nil {
+		filterStart := time.Now()
+		stats.TableFetchDuration = filterStart.Sub(fetchStart)
+		stats.TableRoutes = len(baseRoutes)
+		defer func() { stats.FilterDuration = time.Since(filterStart) }()
+	}
+
+	var seen map[equalKey]struct{}
+	if query.deduplicate {
+		seen = make(map[equalKey]struct{})
 	}
 
 	// 3. 聚合信息并执行过滤
-	routes := make([]*Route, 0, len(baseRoutes))
+	var route Route
+	matched := 0
 	for i := range baseRoutes {
 		baseRoute := &baseRoutes[i]
 
@@ -120,70 +679,521 @@ func GetRoutes(filters ...FilterOption) ([]*Route, error) {
 		}
 
 		// 构建我们自己的 "富对象" Route
-		route := &Route{
-			Destination: baseRoute.DestinationPrefix.Prefix(),
-			NextHop:     baseRoute.NextHop.Addr(),
-			Interface:   iface,
-			Metric:      baseRoute.Metric,
-			Protocol:    baseRoute.Protocol,
-			Origin:      baseRoute.Origin,
-		}
+		route = newRoute(baseRoute, iface)
 
 		// 应用所有过滤器
 		matches := true
 		for _, filter := range filters {
-			if !filter.match(route) {
+			if !filter.match(&route) {
 				matches = false
 				break
 			}
 		}
 
-		if matches {
-			routes = append(routes, route)
+		if !matches {
+			continue
+		}
+
+		if seen != nil {
+			key := equalKeyOf(&route)
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+
+		if query.includeRawRow {
+			raw := *baseRoute
+			route.raw = &raw
+		}
+
+		if stats != nil {
+			stats.MatchedRoutes++
+		}
+		if !fn(&route) {
+			break
+		}
+		if matched++; matched == query.limit {
+			break
 		}
 	}
 
-	return routes, nil
+	return nil
+}
+
+// persistentRouteTable 将注册表中的持久路由转换为路由行，出接口由 persistroute.SelectInterface 在 cache 中确定。
+// 无法解析的注册表值和找不到出接口的路由被跳过。注册表只保存 IPv4 持久路由，family 为 AF_INET6 时结果为空。
+func persistentRouteTable(cache *interfaceCache, family winapi.AddressFamily) ([]winapi.MibIPforwardRow2, error) {
+	if family == winapi.AF_INET6 {
+		return nil, nil
+	}
+	names, err := provider.persistentRoutes()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([][]netip.Prefix, len(cache.all))
+	for i, iface := range cache.all {
+		addresses[i] = iface.Addresses
+	}
+	rows := make([]winapi.MibIPforwardRow2, 0, len(names))
+	for _, name := range names {
+		entry, err := persistroute.Parse(name)
+		if err != nil {
+			// 不是 route -p 写入的值，忽略
+			continue
+		}
+		i := persistroute.SelectInterface(entry, addresses)
+		if i < 0 {
+			// 路由所在的网卡当前不可用，无法确定出接口
+			continue
+		}
+		var row winapi.MibIPforwardRow2
+		row.Init()
+		row.InterfaceLUID = cache.all[i].LUID
+		row.InterfaceIndex = cache.all[i].Index
+		if err := row.DestinationPrefix.SetPrefix(entry.Destination); err != nil {
+			return nil, err
+		}
+		if err := row.NextHop.SetAddr(entry.Gateway); err != nil {
+			return nil, err
+		}
+		row.Metric = entry.Metric
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// newRoute 由 winipcfg 的原始路由行和其所属接口构建 Route。
+func newRoute(row *winapi.MibIPforwardRow2, iface *Interface) Route {
+	destination := row.DestinationPrefix.Prefix()
+	nextHop := row.NextHop.Addr()
+	if destination.Addr().Is4() {
+		// IPv4 路由的下一跳统一为 IPv4 形式；IPv6 路由表中的 ::ffff:0:0/96 等是真正的 IPv6 路由，保持原样
+		nextHop = nextHop.Unmap()
+	}
+	return Route{
+		Destination: destination,
+		NextHop:     nextHop,
+		Interface:   iface,
+		Metric:      row.Metric,
+		Protocol:    row.Protocol,
+		Origin:      row.Origin,
+
+		ValidLifetime:     lifetime.FromSeconds(row.ValidLifetime),
+		PreferredLifetime: lifetime.FromSeconds(row.PreferredLifetime),
+		Age:               time.Duration(row.Age) * time.Second,
+		PreferredSource:   srcaddr.Select(iface.Addresses, destination, nextHop),
+		AutomaticMetric:   row.Metric == 0 && iface.usesAutomaticMetric(destination.Addr()),
+		Loopback:          row.Loopback,
+		Publish:           row.Publish,
+	}
 }
 
 // ---- AddRoute: 增加路由 ----
 
+// onLinkNextHop 在 nextHop 为零值（!nextHop.IsValid()）时返回与 destination 同族的未指定地址
+// （0.0.0.0 或 ::），即 Windows 表示链路直连（on-link）路由的下一跳；否则原样返回 nextHop。
+func onLinkNextHop(destination netip.Prefix, nextHop netip.Addr) netip.Addr {
+	if nextHop.IsValid() {
+		return nextHop
+	}
+	if destination.Addr().Is4() {
+		return netip.IPv4Unspecified()
+	}
+	return netip.IPv6Unspecified()
+}
+
+// resolveNextHopZone 将下一跳地址中的 IPv6 zone（如 fe80::1%5 或 fe80::1%以太网）
+// 映射为接口索引，并检查它是否与 ifaceIndex 一致。IPv4 映射的 IPv6 地址会先转换为 IPv4 地址。
+// cache 可以为 nil，此时仅在需要按别名解析 zone 时才构建接口缓存。
+func resolveNextHopZone(nextHop netip.Addr, ifaceIndex uint32, cache *interfaceCache) (netip.Addr, error) {
+	return scope.ResolveNextHop(nextHop.Unmap(), ifaceIndex, func(alias string) (uint32, error) {
+		if cache == nil {
+			var err error
+			if cache, err = buildInterfaceCache(); err != nil {
+				return 0, err
+			}
+		}
+		iface, err := cache.resolveInterface(alias)
+		if err != nil {
+			return 0, err
+		}
+		return iface.Index, nil
+	})
+}
+
 // AddRoute 添加一条新路由。
 // ifaceIndex 是index。
+// 如果 nextHop 带有 zone（例如 IPv6 链路本地地址 fe80::1%5），zone 必须指向同一个接口。
+// nextHop 为零值（netip.Addr{}）时添加链路直连（on-link）路由：下一跳写为与 destination 同族的
+// 未指定地址（0.0.0.0 或 ::），系统不经过网关，而是直接在 ifaceIndex 所在链路上对目标地址做
+// ARP/邻居发现后发送，适用于点对点或 VPN 隧道接口。这与 route.exe 中以 0.0.0.0 作为网关的效果相同，
+// 路由表中读回的 NextHop 也是该未指定地址。DeleteRoute 对零值 nextHop 的处理与此一致。
+// opts 可以传入 WithRetry 创建的 RetryPolicy，在暂时性错误时重试；
+// 也可以传入 WaitForVisible 创建的 VisibilityWait，等待新路由出现在路由表中后再返回；
+// 传入 RequireInterfaceUp 则在接口未运行时拒绝添加。
+// destination 中设置的主机位会被清除（例如 10.0.0.5/8 按 10.0.0.0/8 添加），见 AddRouteSpecWarn。
+// nextHop 与 destination 必须属于同一地址族。系统以 ERROR_INVALID_PARAMETER 拒绝路由时，
+// 错误信息会说明可能的原因，例如接口上未启用该地址族。
 // 注意：通过此 API 添加的路由在系统重启后不会保留（非持久化）。
-func AddRoute(destination netip.Prefix, nextHop netip.Addr, ifaceIndex uint32, metric uint32) error {
-	luid, err := winipcfg.LUIDFromIndex(ifaceIndex)
+func AddRoute(destination netip.Prefix, nextHop netip.Addr, ifaceIndex uint32, metric uint32, opts ...any) error {
+	return AddRouteSpec(RouteSpec{
+		Destination:    destination,
+		NextHop:        nextHop,
+		InterfaceIndex: ifaceIndex,
+		Metric:         metric,
+	}, opts...)
+}
+
+// AddRouteByLUID 与 AddRoute 相同，但用 LUID 指定接口。适配器断开重连后接口索引可能改变，
+// 而 LUID 保持不变，因此保存下来供以后使用的接口应当记录 LUID 而不是索引。
+// 接口不存在时返回 ErrNotFound。nextHop 带有 zone 时，zone 必须指向该接口当前的索引。
+func AddRouteByLUID(destination netip.Prefix, nextHop netip.Addr, luid winapi.LUID, metric uint32, opts ...any) error {
+	params, err := extractAddParameters(opts...)
+	if err != nil {
+		return err
+	}
+	index, err := LUIDToIndex(luid)
+	if err != nil {
+		return err
+	}
+	params.luid = luid
+	_, err = addRoute(RouteSpec{
+		Destination:    destination,
+		NextHop:        nextHop,
+		InterfaceIndex: index,
+		Metric:         metric,
+	}, nil, params)
+	return err
+}
+
+// AddRouteSpec 按照 RouteSpec 描述添加一条新路由。
+// 与 AddRoute 相比，它还支持设置路由的有效期和首选期。opts 与 AddRoute 相同。
+func AddRouteSpec(spec RouteSpec, opts ...any) error {
+	params, err := extractAddParameters(opts...)
 	if err != nil {
-		return fmt.Errorf("failed to convert interface index to LUID: %w", err)
+		return err
+	}
+	_, err = addRoute(spec, nil, params)
+	return err
+}
+
+// AddRouteSpecWarn 与 AddRouteSpec 相同，但额外返回警告信息，
+// 例如 spec.Destination 设置了主机位（如 10.0.0.5/8）而被规范化为 10.0.0.0/8。
+// 所有添加路由的函数都会进行同样的规范化，此变体只是把它告知调用方。
+func AddRouteSpecWarn(spec RouteSpec, opts ...any) (warnings []string, err error) {
+	if _, warning := normalizeDestination(spec.Destination); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	return warnings, AddRouteSpec(spec, opts...)
+}
+
+// normalizeDestination 将 IPv4 映射的 IPv6 前缀转换为 IPv4 前缀（见 unmap.Prefix），并清除前缀中的主机位。
+// 主机位被清除时返回描述该修改的警告，否则返回空字符串。
+func normalizeDestination(destination netip.Prefix) (netip.Prefix, string) {
+	destination = unmap.Prefix(destination)
+	masked := destination.Masked()
+	if masked == destination {
+		return destination, ""
+	}
+	return masked, fmt.Sprintf("destination %s has host bits set; normalized to %s", destination, masked)
+}
+
+// addRoute 是 AddRouteSpec 和 AddRoutes 的公共实现。
+// cache 可以为 nil；批量操作传入共享的缓存，以免为每条路由重复查询接口信息。
+// 返回此后应继续使用的缓存：接口索引过期时它是 currentLUID 重建的缓存，调用方应以它替换原缓存，
+// 使同一批操作最多重建一次。出错时也会返回该缓存。
+// 只有创建路由的系统调用会按 params.retry 重试。
+func addRoute(spec RouteSpec, cache *interfaceCache, params routeParameters) (*interfaceCache, error) {
+	spec.Destination, _ = normalizeDestination(spec.Destination)
+	// 每次添加都重新解析 LUID，避免缓存中过期的索引对应关系把路由加到错误的适配器上
+	luid, cache, err := currentLUID(spec.InterfaceIndex, cache)
+	if err != nil {
+		return cache, err
+	}
+	if params.luid != 0 && luid != params.luid {
+		return cache, fmt.Errorf("interface index %d no longer belongs to interface LUID %d", spec.InterfaceIndex, params.luid)
+	}
+	nextHop, err := resolveNextHopZone(onLinkNextHop(spec.Destination, spec.NextHop), spec.InterfaceIndex, cache)
+	if err != nil {
+		return cache, err
+	}
+	if nextHop.Is4() != spec.Destination.Addr().Is4() {
+		return cache, fmt.Errorf("next hop %s and destination %s belong to different address families", nextHop, spec.Destination)
+	}
+	if spec.PreferredSource.IsValid() {
+		if cache, err = validatePreferredSource(spec, cache); err != nil {
+			return cache, err
+		}
+	}
+	if params.iface == RequireInterfaceUp {
+		if cache, err = requireInterfaceUp(spec.InterfaceIndex, cache); err != nil {
+			return cache, err
+		}
+	}
+	validLifetime, preferredLifetime, err := lifetime.ToSeconds(spec.ValidLifetime, spec.PreferredLifetime)
+	if err != nil {
+		return cache, err
 	}
 	// 填充 winipcfg 需要的结构体
-	if err := luid.AddRoute(destination, nextHop, metric); err != nil {
+	row := &winapi.MibIPforwardRow2{}
+	row.Init()
+	row.InterfaceLUID = luid
+	if err := row.DestinationPrefix.SetPrefix(spec.Destination); err != nil {
+		return cache, fmt.Errorf("invalid destination %s: %w", spec.Destination, err)
+	}
+	if err := row.NextHop.SetAddr(nextHop); err != nil {
+		return cache, fmt.Errorf("invalid next hop %s: %w", nextHop, err)
+	}
+	row.Metric = spec.Metric
+	row.ValidLifetime = validLifetime
+	row.PreferredLifetime = preferredLifetime
+	row.Loopback = spec.Loopback
+	row.Publish = spec.Publish
+
+	err = params.retry.do(func() error {
+		return provider.createRoute(row)
+	})
+	logSyscall("CreateIpForwardEntry2", err, "destination", spec.Destination, "nextHop", nextHop, "index", spec.InterfaceIndex)
+	if err != nil {
 		// 检查是否因为路由已存在而失败
-		if errors.Is(err, windows.ERROR_OBJECT_ALREADY_EXISTS) {
-			return fmt.Errorf("route to %s already exists: %w", destination, err)
+		if errors.Is(err, winapi.ERROR_OBJECT_ALREADY_EXISTS) {
+			return cache, fmt.Errorf("route to %s already exists: %w", spec.Destination, err)
+		}
+		if errors.Is(err, winapi.ERROR_INVALID_PARAMETER) {
+			return cache, fmt.Errorf("failed to create route: %s: %w", invalidParameterCause(spec, cache), err)
 		}
-		return fmt.Errorf("failed to create route: %w", err)
+		return cache, fmt.Errorf("failed to create route: %w", mapAccessDenied(err))
 	}
 
+	if params.visibility.timeout > 0 {
+		return cache, waitForRoute(cache, spec.Destination, nextHop, luid, params.visibility.timeout)
+	}
+	return cache, nil
+}
+
+// validatePreferredSource 检查 spec.PreferredSource 是接口上与目标同族的单播地址。
+// cache 为 nil 时构建接口缓存，并返回所用的缓存供后续步骤复用。
+func validatePreferredSource(spec RouteSpec, cache *interfaceCache) (*interfaceCache, error) {
+	source := spec.PreferredSource.WithZone("").Unmap()
+	if source.Is4() != spec.Destination.Addr().Is4() {
+		return cache, fmt.Errorf("preferred source %s and destination %s belong to different address families", source, spec.Destination)
+	}
+	if cache == nil {
+		var err error
+		if cache, err = buildInterfaceCache(); err != nil {
+			return nil, err
+		}
+	}
+	iface, ok := cache.byIndex[spec.InterfaceIndex]
+	if !ok {
+		return cache, fmt.Errorf("interface with index %d not found: %w", spec.InterfaceIndex, ErrNotFound)
+	}
+	if !slices.ContainsFunc(iface.Addresses, func(p netip.Prefix) bool { return p.Addr() == source }) {
+		return cache, fmt.Errorf("preferred source %s is not an address of interface %d (%s)", source, iface.Index, iface.Alias)
+	}
+	return cache, nil
+}
+
+// requireInterfaceUp 检查接口 ifaceIndex 处于运行状态。
+// cache 为 nil 时构建接口缓存，并返回所用的缓存供后续步骤复用。
+func requireInterfaceUp(ifaceIndex uint32, cache *interfaceCache) (*interfaceCache, error) {
+	if cache == nil {
+		var err error
+		if cache, err = buildInterfaceCache(); err != nil {
+			return nil, err
+		}
+	}
+	iface, ok := cache.byIndex[ifaceIndex]
+	if !ok {
+		return cache, fmt.Errorf("interface with index %d not found: %w", ifaceIndex, ErrNotFound)
+	}
+	if !iface.IsUp() {
+		return cache, fmt.Errorf("interface %d (%s): %w", iface.Index, iface.Alias, ErrInterfaceDown)
+	}
+	return cache, nil
+}
+
+// invalidParameterCause 在 CreateIpForwardEntry2 返回 ERROR_INVALID_PARAMETER 时推断可能的原因。
+// 最常见的情况是接口上未启用路由所属的地址族（例如在禁用了 IPv6 的网卡上添加 IPv6 路由）。
+func invalidParameterCause(spec RouteSpec, cache *interfaceCache) string {
+	family := "IPv4"
+	if spec.Destination.Addr().Is6() {
+		family = "IPv6"
+	}
+	if cache == nil {
+		var err error
+		if cache, err = buildInterfaceCache(); err != nil {
+			return "invalid parameter"
+		}
+	}
+	iface, ok := cache.byIndex[spec.InterfaceIndex]
+	if !ok {
+		return fmt.Sprintf("interface %d no longer exists", spec.InterfaceIndex)
+	}
+	if !iface.familyEnabled(spec.Destination.Addr()) {
+		return fmt.Sprintf("%s is not enabled on interface %d (%s)", family, iface.Index, iface.Alias)
+	}
+	return fmt.Sprintf("the system rejected the route; check that next hop %s is valid on interface %d (%s)",
+		onLinkNextHop(spec.Destination, spec.NextHop), iface.Index, iface.Alias)
+}
+
+// AddRouteR 与 AddRoute 相同，但在成功后读回系统中刚创建的路由，
+// 返回包含接口信息、协议和来源的完整 Route。
+func AddRouteR(destination netip.Prefix, nextHop netip.Addr, ifaceIndex uint32, metric uint32) (*Route, error) {
+	destination, _ = normalizeDestination(destination)
+	nextHop, err := resolveNextHopZone(onLinkNextHop(destination, nextHop), ifaceIndex, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := AddRoute(destination, nextHop, ifaceIndex, metric); err != nil {
+		return nil, err
+	}
+	return readRoute(destination, nextHop, ifaceIndex)
+}
+
+// readRoute 从系统中读取一条精确匹配的路由，并用接口缓存补全接口信息。
+func readRoute(destination netip.Prefix, nextHop netip.Addr, ifaceIndex uint32) (*Route, error) {
+	luid, err := luidFromIndex(ifaceIndex, nil)
+	if err != nil {
+		return nil, err
+	}
+	row, err := getRouteRow(luid, destination, nextHop, ifaceIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return nil, err
+	}
+	iface, ok := cache.byLUID[row.InterfaceLUID]
+	if !ok {
+		return nil, fmt.Errorf("interface with index %d not found: %w", ifaceIndex, ErrNotFound)
+	}
+
+	route := newRoute(row, iface)
+	return &route, nil
+}
+
+// AddExpiringRoute 与 AddRoute 相同，但路由在 ttl 后自动失效，适合临时的隧道分流。
+// ttl 必须大于 0，不足一秒的部分向上取整。
+//
+// 路由的有效期和首选期都设置为 ttl。有效期到期后系统会自行从路由表中删除该路由，
+// 无需调用方清理；系统路由表中的剩余有效期可通过 Route.ValidLifetime 读取。
+// 由于通过此 API 添加的路由本身不会持久化，系统在 ttl 到期前重启时路由同样会消失。
+func AddExpiringRoute(destination netip.Prefix, nextHop netip.Addr, ifaceIndex uint32, metric uint32, ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("invalid ttl %s: must be positive", ttl)
+	}
+	return AddRouteSpec(RouteSpec{
+		Destination:       destination,
+		NextHop:           nextHop,
+		InterfaceIndex:    ifaceIndex,
+		Metric:            metric,
+		ValidLifetime:     ttl,
+		PreferredLifetime: ttl,
+	})
+}
+
+// AddRouteTemp 与 AddRoute 相同，但在成功后返回一个删除该路由的 cleanup 函数，适合配合 defer 使用。
+//
+// cleanup 删除的正是本次添加的路由：目标、下一跳以及添加时解析出的接口 LUID 都被固定下来，
+// 因此即使接口索引随后被复用也不会误删其他路由。cleanup 可以多次调用，只有第一次会执行删除，
+// 之后的调用返回第一次的结果。
+func AddRouteTemp(destination netip.Prefix, nextHop netip.Addr, ifaceIndex uint32, metric uint32, opts ...any) (cleanup func() error, err error) {
+	destination, _ = normalizeDestination(destination)
+	nextHop, err = resolveNextHopZone(onLinkNextHop(destination, nextHop), ifaceIndex, nil)
+	if err != nil {
+		return nil, err
+	}
+	luid, err := luidFromIndex(ifaceIndex, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := AddRoute(destination, nextHop, ifaceIndex, metric, opts...); err != nil {
+		return nil, err
+	}
+
+	return sync.OnceValue(func() error {
+		return deleteRoute(luid, destination, nextHop, ifaceIndex)
+	}), nil
+}
+
+// getRouteRow 读取接口 luid 上精确匹配的原始路由行。ifaceIndex 仅用于日志。
+func getRouteRow(luid winapi.LUID, destination netip.Prefix, nextHop netip.Addr, ifaceIndex uint32) (*winapi.MibIPforwardRow2, error) {
+	row, err := provider.route(luid, destination, nextHop)
+	logSyscall("GetIpForwardEntry2", err, "destination", destination, "nextHop", nextHop, "index", ifaceIndex)
+	if err != nil {
+		if errors.Is(err, winapi.ERROR_NOT_FOUND) {
+			return nil, fmt.Errorf("route to %s not found: %w", destination, ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to read route: %w", err)
+	}
+	return row, nil
+}
+
+// setRouteRow 将修改后的路由行写回系统（SetIpForwardEntry2），用于原地更新 metric 等属性。
+// ifaceIndex 仅用于日志。
+func setRouteRow(row *winapi.MibIPforwardRow2, ifaceIndex uint32) error {
+	destination := row.DestinationPrefix.Prefix()
+	err := provider.setRoute(row)
+	logSyscall("SetIpForwardEntry2", err, "destination", destination, "nextHop", row.NextHop.Addr(), "index", ifaceIndex, "metric", row.Metric)
+	if err != nil {
+		if errors.Is(err, winapi.ERROR_NOT_FOUND) {
+			return fmt.Errorf("route to %s not found: %w", destination, ErrNotFound)
+		}
+		return fmt.Errorf("failed to update route: %w", mapAccessDenied(err))
+	}
 	return nil
 }
 
 // ---- DeleteRoute: 删除路由 ----
 
+// mapAccessDenied 将 ERROR_ACCESS_DENIED 包装为 ErrAccessDenied，
+// 以便调用方通过 errors.Is(err, ErrAccessDenied) 判断是否需要提升权限。其他错误原样返回。
+func mapAccessDenied(err error) error {
+	if errors.Is(err, winapi.ERROR_ACCESS_DENIED) {
+		return fmt.Errorf("%w: %w", ErrAccessDenied, err)
+	}
+	return err
+}
+
 // DeleteRoute 删除一条精确匹配的路由。
 // 所有参数（目标、下一跳、接口）都必须匹配才能成功删除。
+// nextHop 的 zone 处理方式与 AddRoute 相同；destination 的规范化方式也与 AddRoute 相同。
 func DeleteRoute(destination netip.Prefix, nextHop netip.Addr, ifaceIndex uint32) error {
-	luid, err := winipcfg.LUIDFromIndex(ifaceIndex)
+	destination, _ = normalizeDestination(destination)
+	nextHop, err := resolveNextHopZone(onLinkNextHop(destination, nextHop), ifaceIndex, nil)
+	if err != nil {
+		return err
+	}
+	luid, err := luidFromIndex(ifaceIndex, nil)
 	if err != nil {
-		return fmt.Errorf("failed to convert interface index to LUID: %w", err)
+		return err
 	}
+	return deleteRoute(luid, destination, nextHop, ifaceIndex)
+}
+
+// DeleteRouteWarn 与 DeleteRoute 相同，但额外返回警告信息（含义同 AddRouteSpecWarn）。
+func DeleteRouteWarn(destination netip.Prefix, nextHop netip.Addr, ifaceIndex uint32) (warnings []string, err error) {
+	if _, warning := normalizeDestination(destination); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	return warnings, DeleteRoute(destination, nextHop, ifaceIndex)
+}
 
-	if err := luid.DeleteRoute(destination, nextHop); err != nil {
+// deleteRoute 删除接口 luid 上精确匹配的路由。nextHop 应已去除 zone；ifaceIndex 仅用于日志。
+func deleteRoute(luid winapi.LUID, destination netip.Prefix, nextHop netip.Addr, ifaceIndex uint32) error {
+	err := provider.deleteRoute(luid, destination, nextHop)
+	logSyscall("DeleteIpForwardEntry2", err, "destination", destination, "nextHop", nextHop, "index", ifaceIndex)
+	if err != nil {
 		// 检查是否因为路由不存在而失败
-		if errors.Is(err, windows.ERROR_NOT_FOUND) {
+		if errors.Is(err, winapi.ERROR_NOT_FOUND) {
 			return fmt.Errorf("route to %s not found: %w", destination, ErrNotFound)
 		}
-		return fmt.Errorf("failed to delete route: %w", err)
+		return fmt.Errorf("failed to delete route: %w", mapAccessDenied(err))
 	}
 
 	return nil
@@ -202,59 +1212,366 @@ const (
 	ErrorActionStop
 )
 
-// extractRouteParameters 从选项列表中解析出过滤器和错误处理行为。
-func extractRouteParameters(opts ...any) ([]FilterOption, ErrorAction, error) {
-	var filters []FilterOption
-	errorAction := ErrorActionContinue // 默认行为
+// DeleteScope 控制 DeleteRoutes 在没有任何 FilterOption 时的行为。
+type DeleteScope int
+
+const (
+	// RequireFilter 表示必须至少提供一个 FilterOption，否则 DeleteRoutes 返回 ErrNoFilter。
+	// 这是默认行为，用于防止误删整个路由表。
+	RequireFilter DeleteScope = iota
+	// AllowDeleteAll 表示允许在没有过滤器时删除所有路由。请谨慎使用。
+	AllowDeleteAll
+)
+
+// DeleteLimit 控制 DeleteRoutes 在多条路由匹配时删除多少条。
+type DeleteLimit int
+
+const (
+	// DeleteAllMatches 表示删除所有匹配的路由。这是默认行为。
+	DeleteAllMatches DeleteLimit = iota
+	// DeleteOne 表示只删除一条路由：前缀最长的路由，前缀长度相同时取 metric 最小的路由。
+	// 如果这一规则仍无法选出唯一的路由，DeleteRoutes 返回 ErrAmbiguousMatch，不会删除任何路由。
+	DeleteOne
+)
+
+// InterfaceRequirement 控制增加路由时是否检查接口的运行状态。
+type InterfaceRequirement int
+
+const (
+	// AllowInterfaceDown 表示不检查接口状态，在未运行的接口上也能添加路由。这是默认行为。
+	AllowInterfaceDown InterfaceRequirement = iota
+	// RequireInterfaceUp 表示接口未处于运行状态（OperStatus 不是 IfOperStatusUp）时拒绝添加路由，
+	// 返回包装了 ErrInterfaceDown 的错误。这样的路由虽然能创建，但在接口恢复前无法承载流量。
+	RequireInterfaceUp
+)
+
+// ErrNoFilter 表示调用 DeleteRoutes 时没有提供任何过滤器，且未显式传入 AllowDeleteAll。
+// IncludeRawRow、Deduplicate、Limit、WithAddressFamily 和 WithStore 不缩小匹配的路由范围，不算作过滤器。
+var ErrNoFilter = errors.New("no filter provided; pass AllowDeleteAll to delete every route")
+
+// routeParameters 是从批量操作的选项列表中解析出的参数。
+type routeParameters struct {
+	filters     []FilterOption
+	errorAction ErrorAction
+	scope       DeleteScope
+	limit       DeleteLimit
+	retry       RetryPolicy
+	visibility  VisibilityWait
+	progress    ProgressReporter
+	iface       InterfaceRequirement
+	rateLimit   RateLimitPolicy
+	// luid 不为 0 时要求 InterfaceIndex 仍对应该 LUID，由 AddRouteByLUID 设置
+	luid winapi.LUID
+}
+
+// extractRouteParameters 从选项列表中解析出过滤器和行为选项。
+func extractRouteParameters(opts ...any) (routeParameters, error) {
+	params := routeParameters{
+		errorAction: ErrorActionContinue, // 默认行为
+		scope:       RequireFilter,
+	}
 
 	for _, opt := range opts {
 		switch o := opt.(type) {
 		case FilterOption:
-			filters = append(filters, o)
+			params.filters = append(params.filters, o)
 		case ErrorAction:
-			errorAction = o
+			params.errorAction = o
+		case DeleteScope:
+			params.scope = o
+		case DeleteLimit:
+			params.limit = o
+		case RetryPolicy:
+			params.retry = o
+		case VisibilityWait:
+			params.visibility = o
+		case ProgressReporter:
+			params.progress = o
+		case InterfaceRequirement:
+			params.iface = o
+		case RateLimitPolicy:
+			if o.perSecond <= 0 {
+				return routeParameters{}, fmt.Errorf("invalid rate limit %d: must be positive", o.perSecond)
+			}
+			params.rateLimit = o
 		default:
-			return nil, 0, fmt.Errorf("unsupported option type: %T", o)
+			return routeParameters{}, fmt.Errorf("unsupported option type: %T", o)
 		}
 	}
 
-	return filters, errorAction, nil
+	return params, nil
+}
+
+// extractAddParameters 解析增加路由时的选项，增加路由不接受过滤器、DeleteScope 和 DeleteLimit。
+func extractAddParameters(opts ...any) (routeParameters, error) {
+	for _, opt := range opts {
+		switch opt.(type) {
+		case FilterOption, DeleteScope, DeleteLimit:
+			return routeParameters{}, fmt.Errorf("unsupported option type for adding routes: %T", opt)
+		}
+	}
+	return extractRouteParameters(opts...)
 }
 
 // DeleteRoutes 按照一组过滤器和行为选项删除路由。
 //
-// opts 参数可以接收两种类型的选项：
+// opts 参数可以接收以下类型的选项：
 //   - FilterOption: 用于指定要删除哪些路由 (例如 WithDestinationPrefix, WithInterfaceAlias)。
 //   - ErrorAction: 用于配置删除过程的行为 (ErrorActionContinue 或 ErrorActionStop)。
+//   - DeleteScope: 传入 AllowDeleteAll 以允许在没有过滤器时删除所有路由。
+//   - DeleteLimit: 传入 DeleteOne 以在多条路由匹配时只删除最具体的一条。
+//   - RetryPolicy: 由 WithRetry 创建，对暂时性错误重试删除。
+//   - ProgressReporter: 由 WithProgress 创建，每删除（或删除失败）一条路由报告一次进度。
+//   - RateLimitPolicy: 由 RateLimit 创建，限制每秒删除的路由数。
 //
 // 默认行为是“继续执行并聚合所有错误”（ErrorActionContinue）。
+// 如果没有提供任何缩小匹配范围的 FilterOption 且未传入 AllowDeleteAll，则返回 ErrNoFilter，不会删除任何路由；
+// IncludeRawRow、Deduplicate、Limit、WithAddressFamily 和 WithStore 不算作这样的过滤器；
+// 传入 WithStore(StorePersistent) 时返回错误，持久路由不能通过 DeleteRoutes 删除。
 //
 // 返回值:
 //   - partialErrs ([]error): 在 ContinueOnError 模式下，收集所有删除失败的错误。如果全部成功，则为 nil。
 //   - err (error): 操作过程中的致命错误（如无法获取路由列表）。在 ContinueOnError 模式下，即使有部分删除失败，此错误也为 nil。
 func DeleteRoutes(opts ...any) (partialErrs []error, err error) {
-	filters, errorAction, err := extractRouteParameters(opts...)
+	params, routes, err := routesToDelete(opts)
+	if err != nil || len(routes) == 0 {
+		return nil, err
+	}
+	return routeops.DeleteRoutes(
+		routes,
+		withProgress(params.progress, len(routes), identityRoute, withRateLimit(params.rateLimit, func(route *Route) error {
+			return params.retry.do(route.Delete)
+		})),
+		(*Route).String,
+		routeops.ErrorAction(params.errorAction),
+	)
+}
+
+// DeleteRoutesBatch 与 DeleteRoutes 相同，但以 BatchResult 汇总每条路由的删除结果。
+// 使用 ErrorActionStop 时，导致停止的那次失败同样记录在结果中，而不是作为 err 返回；
+// err 只表示未能开始删除的致命错误（如 ErrNoFilter 或无法获取路由列表）。
+func DeleteRoutesBatch(opts ...any) (*BatchResult, error) {
+	params, routes, err := routesToDelete(opts)
 	if err != nil {
 		return nil, err
 	}
+	result := &BatchResult{}
+	partialErrs, stopErr := routeops.DeleteRoutes(
+		routes,
+		withProgress(params.progress, len(routes), identityRoute, withRateLimit(params.rateLimit, countSucceeded(result, func(route *Route) error {
+			return params.retry.do(route.Delete)
+		}))),
+		(*Route).String,
+		routeops.ErrorAction(params.errorAction),
+	)
+	result.collect(partialErrs, stopErr)
+	return result, nil
+}
 
-	routes, err := GetRoutes(filters...)
+// routesToDelete 解析 DeleteRoutes 的参数，并找出要删除的路由。
+func routesToDelete(opts []any) (routeParameters, []*Route, error) {
+	params, err := extractRouteParameters(opts...)
+	if err != nil {
+		return params, nil, err
+	}
+	if !narrowsMatch(params.filters) && params.scope != AllowDeleteAll {
+		return params, nil, ErrNoFilter
+	}
+	if err := requireActiveStore(params.filters); err != nil {
+		return params, nil, err
+	}
+	cache, err := buildInterfaceCache()
 	if err != nil {
-		return nil, fmt.Errorf("failed to find routes for deletion: %w", err)
+		return params, nil, err
+	}
+	routes, err := getRoutes(cache, params.filters)
+	if err != nil {
+		return params, nil, fmt.Errorf("failed to find routes for deletion: %w", err)
 	}
 
-	if len(routes) == 0 {
-		return nil, nil
+	if len(routes) > 1 && params.limit == DeleteOne {
+		best, count := bestmatch.MostSpecific(
+			routes,
+			func(r *Route) netip.Prefix { return r.Destination },
+			func(r *Route) uint32 { return r.Metric },
+		)
+		if count > 1 {
+			return params, nil, fmt.Errorf("%w: %d routes match with prefix length %d and metric %d",
+				ErrAmbiguousMatch, count, best.Destination.Bits(), best.Metric)
+		}
+		routes = []*Route{best}
 	}
+	return params, routes, nil
+}
 
-	return routeops.DeleteRoutes(
-		routes,
+// narrowsMatch 判断 filters 中是否有真正缩小匹配范围的过滤器。
+// 实现 queryModifier 的选项（IncludeRawRow、Deduplicate、Limit、WithAddressFamily 和 WithStore）不算：
+// 只传入它们时，删除的仍是整个路由表（或某个地址族的全部路由）。
+func narrowsMatch(filters []FilterOption) bool {
+	for _, filter := range filters {
+		if _, ok := filter.(queryModifier); !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteRoutesFunc 删除 predicate 返回 true 的所有路由，适用于无法用固定过滤器表达的动态条件
+// （例如“下一跳已不可达时才删除”）。predicate 按路由表顺序对每条路由调用一次，
+// 调用时不持有任何锁，可以执行较慢的检查。predicate 不能为 nil。
+//
+// opts 的含义与 DeleteRoutes 的 ErrorAction 相同，默认继续执行并聚合所有错误。
+// deleted 是成功删除的路由数，partialErrs 和 err 的含义与 DeleteRoutes 相同。
+func DeleteRoutesFunc(predicate func(*Route) bool, opts ...ErrorAction) (deleted int, partialErrs []error, err error) {
+	if predicate == nil {
+		return 0, nil, errors.New("predicate must not be nil")
+	}
+	errorAction := ErrorActionContinue
+	for _, opt := range opts {
+		errorAction = opt
+	}
+
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return 0, nil, err
+	}
+	routes, err := getRoutes(cache, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to find routes for deletion: %w", err)
+	}
+	selected := routes[:0]
+	for _, route := range routes {
+		if predicate(route) {
+			selected = append(selected, route)
+		}
+	}
+
+	partialErrs, err = routeops.DeleteRoutes(
+		selected,
 		func(route *Route) error {
-			return route.Delete()
-		},
-		func(route *Route) string {
-			return fmt.Sprintf("dest: %s, iface: %s", route.Destination, route.Interface.Alias)
+			if err := route.Delete(); err != nil {
+				return err
+			}
+			deleted++
+			return nil
 		},
+		(*Route).String,
 		routeops.ErrorAction(errorAction),
 	)
+	return deleted, partialErrs, err
+}
+
+// ---- AddRoutes: 批量增加路由 ----
+
+// AddRouteToInterfaces 在别名与 glob 模式 aliasPattern 匹配（规则同 WithInterfaceAliasPattern）的
+// 每个接口上添加同一条路由，例如在所有 "vEthernet *" 接口上安装冗余路由。
+// 模式不匹配任何接口时返回 ErrNotFound。
+//
+// 各接口上的添加相互独立：某个接口失败不影响其他接口，失败会逐条记录在 partialErrs 中。
+// added 是成功添加路由的接口数。
+func AddRouteToInterfaces(destination netip.Prefix, nextHop netip.Addr, aliasPattern string, metric uint32) (added int, partialErrs []error, err error) {
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return 0, nil, err
+	}
+	ifaces, err := cache.interfacesMatching(aliasPattern)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	specs := make([]RouteSpec, 0, len(ifaces))
+	for _, iface := range ifaces {
+		specs = append(specs, RouteSpec{
+			Destination:    destination,
+			NextHop:        nextHop,
+			InterfaceIndex: iface.Index,
+			Metric:         metric,
+		})
+	}
+	partialErrs, err = routeops.AddRoutes(
+		specs,
+		func(spec RouteSpec) error {
+			var err error
+			if cache, err = addRoute(spec, cache, routeParameters{}); err != nil {
+				return err
+			}
+			added++
+			return nil
+		},
+		describeSpec,
+		routeops.ErrorActionContinue,
+	)
+	return added, partialErrs, err
+}
+
+// describeSpec 在批量添加的错误信息中描述一条待添加的路由。
+func describeSpec(spec RouteSpec) string {
+	return fmt.Sprintf("dest: %s, iface: %d", spec.Destination, spec.InterfaceIndex)
+}
+
+// AddRoutes 批量添加路由。整个调用只构建一次接口缓存，用于解析所有路由的接口。
+//
+// opts 参数接收 ErrorAction，行为与 DeleteRoutes 相同：默认继续执行并聚合所有错误，
+// 传入 ErrorActionStop 则在第一个错误处停止。也可以传入 WithRetry、WaitForVisible、WithProgress 和 RateLimit
+// 创建的选项，以及 RequireInterfaceUp。
+//
+// 返回值的含义与 DeleteRoutes 相同。
+func AddRoutes(specs []RouteSpec, opts ...any) (partialErrs []error, err error) {
+	params, err := extractAddParameters(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return nil, err
+	}
+
+	return routeops.AddRoutes(
+		specs,
+		withProgress(params.progress, len(specs), func(spec RouteSpec) *Route {
+			return routeOfSpec(cache, spec)
+		}, withRateLimit(params.rateLimit, func(spec RouteSpec) error {
+			var err error
+			cache, err = addRoute(spec, cache, params)
+			return err
+		})),
+		describeSpec,
+		routeops.ErrorAction(params.errorAction),
+	)
+}
+
+// AddRoutesBatch 与 AddRoutes 相同，但以 BatchResult 汇总每条路由的添加结果。
+// ErrorActionStop 和 err 的含义与 DeleteRoutesBatch 相同。
+func AddRoutesBatch(specs []RouteSpec, opts ...any) (*BatchResult, error) {
+	params, err := extractAddParameters(opts...)
+	if err != nil {
+		return nil, err
+	}
+	result := &BatchResult{}
+	if len(specs) == 0 {
+		return result, nil
+	}
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return nil, err
+	}
+
+	partialErrs, stopErr := routeops.AddRoutes(
+		specs,
+		withProgress(params.progress, len(specs), func(spec RouteSpec) *Route {
+			return routeOfSpec(cache, spec)
+		}, withRateLimit(params.rateLimit, countSucceeded(result, func(spec RouteSpec) error {
+			var err error
+			cache, err = addRoute(spec, cache, params)
+			return err
+		}))),
+		describeSpec,
+		routeops.ErrorAction(params.errorAction),
+	)
+	result.collect(partialErrs, stopErr)
+	return result, nil
 }
@@ -0,0 +1,81 @@
+package winroute
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// BenchmarkAddRoutes1000 使用伪 provider 对比两种添加 1000 条路由的方式：一次 AddRoutes 批量调用
+// （整个批次共享一个接口缓存），以及逐条调用 AddRoute。系统调用由伪实现代替，
+// 因此耗时只反映本包自身的开销；cache-builds/op 和 luid-lookups/op 指标显示每次调用中
+// 枚举适配器和转换接口索引的次数，在真实系统上它们决定了主要开销。
+func BenchmarkAddRoutes1000(b *testing.B) {
+	specs := make([]RouteSpec, 1000)
+	for i := range specs {
+		specs[i] = RouteSpec{
+			Destination:    netip.PrefixFrom(netip.AddrFrom4([4]byte{198, 18, byte(i >> 8), byte(i)}), 32),
+			InterfaceIndex: 5,
+		}
+	}
+
+	run := func(b *testing.B, add func() error) {
+		f := newFakeProvider(b)
+		useProvider(b, f)
+		for b.Loop() {
+			f.created = f.created[:0]
+			if err := add(); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(len(f.ifaceFamilies))/float64(b.N), "cache-builds/op")
+		b.ReportMetric(float64(f.luidLookups)/float64(b.N), "luid-lookups/op")
+	}
+
+	b.Run("AddRoutes", func(b *testing.B) {
+		run(b, func() error {
+			partialErrs, err := AddRoutes(specs)
+			if err == nil && len(partialErrs) > 0 {
+				err = partialErrs[0]
+			}
+			return err
+		})
+	})
+
+	b.Run("AddRoute", func(b *testing.B) {
+		run(b, func() error {
+			for _, spec := range specs {
+				if err := AddRouteSpec(spec); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// BenchmarkInterfaceCacheFamily 对比构建完整接口缓存与只构建 IPv4 接口缓存的开销，
+// 用于评估 NewRouteTableFamily 的收益。结果取决于主机上的适配器数量，应在适配器很多的主机上运行：
+//
+//	go test -run ^$ -bench InterfaceCacheFamily -count 10
+func BenchmarkInterfaceCacheFamily(b *testing.B) {
+	if _, err := buildInterfaceCache(); err != nil {
+		b.Skipf("interface cache unavailable: %v", err)
+	}
+	for _, bc := range []struct {
+		name   string
+		family winapi.AddressFamily
+	}{
+		{"AF_UNSPEC", winapi.AF_UNSPEC},
+		{"AF_INET", winapi.AF_INET},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := buildInterfaceCacheFamily(bc.family); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package winroute
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// SelfTest 执行一次无副作用的往返检查，确认本包依赖的 winipcfg 调用在当前环境中可用：
+// 枚举网络适配器、获取路由表，并确认至少有一个接口能在路由表和索引转换中被解析。
+// 任何一步失败都会返回说明失败环节的错误。SelfTest 不修改路由，也不需要管理员权限。
+func SelfTest() error {
+	cache, err := newInterfaceCache()
+	if err != nil {
+		return fmt.Errorf("self-test: failed to enumerate network adapters: %w", err)
+	}
+	if len(cache.all) == 0 {
+		return errors.New("self-test: no network interfaces found")
+	}
+
+	rows, err := provider.routeTable(winapi.AF_UNSPEC)
+	if err != nil {
+		return fmt.Errorf("self-test: failed to get route table: %w", err)
+	}
+	resolved := false
+	for i := range rows {
+		if _, ok := cache.byLUID[rows[i].InterfaceLUID]; ok {
+			resolved = true
+			break
+		}
+	}
+	if !resolved {
+		return fmt.Errorf("self-test: none of the %d routes resolve to one of the %d interfaces", len(rows), len(cache.all))
+	}
+
+	// 索引与 LUID 的转换是按索引操作路由的基础，用第一个接口验证一次。
+	iface := cache.all[0]
+	luid, err := provider.luidFromIndex(iface.Index)
+	if err != nil {
+		return fmt.Errorf("self-test: failed to convert interface index %d to LUID: %w", iface.Index, err)
+	}
+	if luid != iface.LUID {
+		return fmt.Errorf("self-test: interface index %d maps to LUID %d, expected %d", iface.Index, luid, iface.LUID)
+	}
+	return nil
+}
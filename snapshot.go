@@ -0,0 +1,159 @@
+package winroute
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+
+	"github.com/bnkrr/winroute/internal/aliasfold"
+	"github.com/bnkrr/winroute/internal/routeops"
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// ---- 路由表快照：导出和恢复 ----
+
+// snapshotVersion 是 ExportRoutes 写出的快照格式版本。
+const snapshotVersion = 1
+
+type snapshot struct {
+	Version int             `json:"version"`
+	Routes  []snapshotRoute `json:"routes"`
+}
+
+type snapshotRoute struct {
+	Destination    netip.Prefix `json:"destination"`
+	NextHop        netip.Addr   `json:"next_hop"`
+	InterfaceIndex uint32       `json:"interface_index"`
+	InterfaceAlias string       `json:"interface_alias"`
+	Metric         uint32       `json:"metric"`
+}
+
+// isManageable 判断路由是否是手动添加的静态路由（来源为手动、协议为 NetMgmt，且不是系统路由），
+// 即 ExportRoutes 会导出、恢复时可以重新添加的路由。
+// 由 DHCP、路由器通告或接口地址自动生成的路由会由系统自行重建，因此不包括在内。
+func (r *Route) isManageable() bool {
+	return r.Origin == winapi.RouteOriginManual &&
+		r.Protocol == winapi.RouteProtocolNetMgmt &&
+		!r.IsSystemRoute()
+}
+
+// ExportRoutes 将当前所有手动添加的静态路由以 JSON 快照写入 w，供 ImportRoutes 恢复。
+// 系统路由以及由 DHCP、路由器通告等自动生成的路由不会被导出。
+// 快照同时记录接口索引和别名，因为接口索引在重启或重装驱动后可能改变。
+func ExportRoutes(w io.Writer) error {
+	routes, err := GetRoutes()
+	if err != nil {
+		return err
+	}
+
+	snap := snapshot{Version: snapshotVersion, Routes: []snapshotRoute{}}
+	for _, route := range routes {
+		if !route.isManageable() {
+			continue
+		}
+		snap.Routes = append(snap.Routes, snapshotRoute{
+			Destination:    route.Destination,
+			NextHop:        route.NextHop.WithZone(""),
+			InterfaceIndex: route.Interface.Index,
+			InterfaceAlias: route.Interface.Alias,
+			Metric:         route.Metric,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(snap); err != nil {
+		return fmt.Errorf("failed to write route snapshot: %w", err)
+	}
+	return nil
+}
+
+// ImportRoutes 读取 ExportRoutes 写出的快照并重新添加其中的路由，返回实际添加的路由数量。
+//
+// 每条路由优先按快照中的接口别名查找接口，别名不存在时再按接口索引查找。
+// 系统中已经存在的路由（目标、下一跳和接口均相同）会被跳过，不计入添加数量，也不视为错误。
+// 路由的添加使用与 AddRoutes 相同的批量引擎，整个调用只构建一次接口缓存；
+// opts 的含义也与 AddRoutes 相同：默认继续执行并为每条失败的路由返回一个错误，
+// 传入 ErrorActionStop 则在第一个错误处停止并将其作为 err 返回。
+func ImportRoutes(r io.Reader, opts ...ErrorAction) (added int, partialErrs []error, err error) {
+	errorAction := ErrorActionContinue
+	for _, opt := range opts {
+		errorAction = opt
+	}
+
+	var snap snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return 0, nil, fmt.Errorf("failed to read route snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return 0, nil, fmt.Errorf("unsupported route snapshot version %d", snap.Version)
+	}
+
+	cache, err := buildInterfaceCache()
+	if err != nil {
+		return 0, nil, err
+	}
+	existing, err := getRoutes(cache, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	present := make(map[routeIdentity]struct{}, len(existing))
+	for _, route := range existing {
+		present[identityOf(route)] = struct{}{}
+	}
+
+	var specs []RouteSpec
+	for _, route := range snap.Routes {
+		iface, err := cache.snapshotInterface(route)
+		if err != nil {
+			err = fmt.Errorf("failed to restore route to %s: %w", route.Destination, err)
+			if errorAction == ErrorActionStop {
+				return 0, nil, err
+			}
+			partialErrs = append(partialErrs, err)
+			continue
+		}
+		spec := RouteSpec{
+			Destination:    route.Destination,
+			NextHop:        route.NextHop,
+			InterfaceIndex: iface.Index,
+			Metric:         route.Metric,
+		}
+		if _, ok := present[specIdentity(spec)]; ok {
+			continue
+		}
+		specs = append(specs, spec)
+	}
+
+	addErrs, err := routeops.AddRoutes(
+		specs,
+		func(spec RouteSpec) error {
+			var err error
+			cache, err = addRoute(spec, cache, routeParameters{})
+			return err
+		},
+		describeSpec,
+		routeops.ErrorAction(errorAction),
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(specs) - len(addErrs), append(partialErrs, addErrs...), nil
+}
+
+// snapshotInterface 查找快照路由所在的接口：优先按别名，其次按索引。
+func (c *interfaceCache) snapshotInterface(route snapshotRoute) (*Interface, error) {
+	if route.InterfaceAlias != "" {
+		if err := validateUniqueAlias(c, route.InterfaceAlias); err != nil {
+			return nil, err
+		}
+		if iface, ok := c.byAlias[aliasfold.Key(route.InterfaceAlias)]; ok {
+			return iface, nil
+		}
+	}
+	if iface, ok := c.byIndex[route.InterfaceIndex]; ok {
+		return iface, nil
+	}
+	return nil, fmt.Errorf("interface '%s' (index %d) not found: %w", route.InterfaceAlias, route.InterfaceIndex, ErrNotFound)
+}
@@ -0,0 +1,41 @@
+package winroute
+
+import "time"
+
+// RouteStats 记录一次路由查询各阶段的耗时和路由数量，由 GetRoutesWithStats 返回。
+// 可以据此判断接口缓存的构建是否是主要开销，从而决定是否改用缓存接口信息的 RouteTable。
+type RouteStats struct {
+	CacheBuildDuration time.Duration // 枚举网络接口、构建接口缓存的耗时
+	TableFetchDuration time.Duration // 从系统获取路由表（GetIPForwardTable2）的耗时
+	FilterDuration     time.Duration // 构建 Route 并执行过滤器的耗时
+	TableRoutes        int           // 系统返回的路由表行数
+	MatchedRoutes      int           // 通过所有过滤器、出现在结果中的路由数
+}
+
+// Total 返回查询的总耗时。
+func (s RouteStats) Total() time.Duration {
+	return s.CacheBuildDuration + s.TableFetchDuration + s.FilterDuration
+}
+
+// GetRoutesWithStats 与 GetRoutes 相同，但额外返回各阶段的耗时统计。
+// 出错时返回的 RouteStats 包含出错前已完成阶段的数据。
+func GetRoutesWithStats(filters ...FilterOption) ([]*Route, RouteStats, error) {
+	var stats RouteStats
+	start := time.Now()
+	cache, err := buildInterfaceCache()
+	stats.CacheBuildDuration = time.Since(start)
+	if err != nil {
+		return nil, stats, err
+	}
+
+	routes := make([]*Route, 0)
+	err = scanRoutesStats(cache, filters, &stats, func(route *Route) bool {
+		r := *route
+		routes = append(routes, &r)
+		return true
+	})
+	if err != nil {
+		return nil, stats, err
+	}
+	return routes, stats, nil
+}
@@ -0,0 +1,143 @@
+package winroute
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// ---- RouteTable: 带缓存和来源登记的路由表 ----
+
+// RouteTable 在创建时构建一次接口缓存，并在其后的查询和修改中复用。
+//
+// 通过 Add 添加成功的路由会被登记在内存中，MyRoutes 只返回这些路由。
+// Windows 路由无法携带自定义标记，这使守护进程可以在退出时准确删除自己创建的路由。
+// 登记只存在于当前进程内，不会跨进程保留。
+//
+// RouteTable 可以被多个 goroutine 并发使用：查询和修改方法只持有接口缓存的读锁，
+// 因此可以并行执行；Refresh 持有写锁，会等待进行中的调用结束，并在重建缓存期间阻塞新的调用。
+type RouteTable struct {
+	cacheMu sync.RWMutex
+	cache   *interfaceCache
+	family  winapi.AddressFamily // 不为 AF_UNSPEC 时只处理该地址族，见 NewRouteTableFamily
+
+	mu    sync.Mutex // 保护 added
+	added map[routeIdentity]struct{}
+
+	notesMu   sync.Mutex // 保护 notesPath，并串行化对注释文件的读写
+	notesPath string     // 为空时使用 DefaultAnnotationPath
+}
+
+// NewRouteTable 创建一个 RouteTable，并构建其接口缓存。
+func NewRouteTable() (*RouteTable, error) {
+	return NewRouteTableFamily(winapi.AF_UNSPEC)
+}
+
+// NewRouteTableFamily 创建一个只处理 family 地址族（windows.AF_INET 或 windows.AF_INET6，
+// AF_UNSPEC 等同于 NewRouteTable）的 RouteTable。
+//
+// 构建接口缓存时只向系统请求该地址族的适配器信息，GetRoutes 和 MyRoutes 也只获取该地址族的路由表。
+// 缓存中只有启用了该地址族的接口，Interface 的地址和网关也只包含该地址族；Add 会拒绝另一地址族的路由。
+// 这能减少系统返回的数据量，但实际节省的时间取决于主机上的适配器，尚未实测；
+// 需要依据时请在目标主机上运行 BenchmarkInterfaceCacheFamily。
+func NewRouteTableFamily(family winapi.AddressFamily) (*RouteTable, error) {
+	if family != winapi.AF_UNSPEC && family != winapi.AF_INET && family != winapi.AF_INET6 {
+		return nil, fmt.Errorf("unsupported address family %d: must be AF_UNSPEC, AF_INET or AF_INET6", family)
+	}
+	cache, err := buildInterfaceCacheFamily(family)
+	if err != nil {
+		return nil, err
+	}
+	return &RouteTable{
+		cache:  cache,
+		family: family,
+		added:  make(map[routeIdentity]struct{}),
+	}, nil
+}
+
+// Refresh 重新构建 RouteTable 的接口缓存，使其反映接口的增删和地址变化。
+// 构建失败时保留原有缓存并返回错误。
+func (t *RouteTable) Refresh() error {
+	t.cacheMu.Lock()
+	defer t.cacheMu.Unlock()
+
+	cache, err := buildInterfaceCacheFamily(t.family)
+	if err != nil {
+		return err
+	}
+	t.cache = cache
+	return nil
+}
+
+// GetRoutes 与包级 GetRoutes 相同，但使用 RouteTable 的接口缓存。
+func (t *RouteTable) GetRoutes(filters ...FilterOption) ([]*Route, error) {
+	t.cacheMu.RLock()
+	defer t.cacheMu.RUnlock()
+	return getRoutes(t.cache, t.familyFilters(filters))
+}
+
+// familyFilters 在 RouteTable 限定了地址族时把对应的地址族过滤器加在 filters 之前，
+// 使查询只获取该地址族的路由表；filters 中的另一地址族过滤器会使结果为空。
+func (t *RouteTable) familyFilters(filters []FilterOption) []FilterOption {
+	if t.family == winapi.AF_UNSPEC {
+		return filters
+	}
+	return append([]FilterOption{WithAddressFamily(t.family)}, filters...)
+}
+
+// Add 与 AddRouteSpec 相同，但使用 RouteTable 的接口缓存，并在成功后登记该路由。
+func (t *RouteTable) Add(spec RouteSpec, opts ...any) error {
+	params, err := extractAddParameters(opts...)
+	if err != nil {
+		return err
+	}
+	if t.family != winapi.AF_UNSPEC && spec.Destination.Addr().Is4() != (t.family == winapi.AF_INET) {
+		return fmt.Errorf("route %s does not belong to the address family of this route table", spec.Destination)
+	}
+	t.cacheMu.RLock()
+	cache := t.cache
+	refreshed, err := addRoute(spec, cache, params)
+	t.cacheMu.RUnlock()
+	if refreshed != cache {
+		// 接口索引已过期，addRoute 重建了缓存；在没有被 Refresh 替换的情况下保存它，
+		// 并清除重建标记，使以后的 Add 仍会在索引再次过期时重建
+		t.cacheMu.Lock()
+		if t.cache == cache {
+			refreshed.rebuilt = false
+			t.cache = refreshed
+		}
+		t.cacheMu.Unlock()
+	}
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.added[specIdentity(spec)] = struct{}{}
+	return nil
+}
+
+// MyRoutes 返回系统中当前存在、且由本 RouteTable 的 Add 创建的路由。
+// 已被删除（无论通过何种方式）的路由不会出现在结果中。
+func (t *RouteTable) MyRoutes() ([]*Route, error) {
+	t.mu.Lock()
+	added := make(map[routeIdentity]struct{}, len(t.added))
+	for id := range t.added {
+		added[id] = struct{}{}
+	}
+	t.mu.Unlock()
+
+	if len(added) == 0 {
+		return nil, nil
+	}
+	t.cacheMu.RLock()
+	defer t.cacheMu.RUnlock()
+	return getRoutes(t.cache, t.familyFilters([]FilterOption{filterOption{
+		matchFn: func(r *Route) bool {
+			_, ok := added[identityOf(r)]
+			return ok
+		},
+	}}))
+}
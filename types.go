@@ -1,19 +1,124 @@
-//go:build windows
-
 package winroute
 
 import (
+	"math"
 	"net/netip"
+	"slices"
+	"time"
 
-	"golang.zx2c4.com/wireguard/windows/tunnel/winipcfg"
+	"github.com/bnkrr/winroute/internal/iftype"
+	"github.com/bnkrr/winroute/internal/lifetime"
+	"github.com/bnkrr/winroute/internal/linkspeed"
+	"github.com/bnkrr/winroute/internal/routeclass"
+	"github.com/bnkrr/winroute/internal/routefmt"
+	"github.com/bnkrr/winroute/internal/winapi"
 )
 
+// InfiniteLifetime 表示永不过期的路由有效期/首选期。
+const InfiniteLifetime = lifetime.Infinite
+
 // Interface 代表一个网络接口的聚合信息。
 type Interface struct {
 	Index       uint32
-	LUID        winipcfg.LUID
+	LUID        winapi.LUID
 	Alias       string // 用户友好的名字, e.g., "以太网"
 	Description string // 接口描述, e.g., "Realtek PCIe GbE Family Controller"
+	// Addresses 是接口上配置的单播地址，前缀长度为该地址的链路前缀长度，e.g., 192.168.1.10/24
+	Addresses []netip.Prefix
+	// OperStatus 是接口的运行状态，e.g., winipcfg.IfOperStatusUp
+	OperStatus winapi.IfOperStatus
+	// IfType 是接口的类型（媒体类型），e.g., winipcfg.IfTypeEthernetCSMACD 或 winipcfg.IfTypeIEEE80211；
+	// 可读的名称见 TypeName
+	IfType winapi.IfType
+	// TransmitSpeed 和 ReceiveSpeed 是接口当前的发送和接收链路速度，单位为 bit/s，e.g., 1000000000；
+	// 系统无法确定时为 0
+	TransmitSpeed uint64
+	ReceiveSpeed  uint64
+	// Gateways 是接口上配置的默认网关，e.g., 192.168.1.1
+	Gateways []netip.Addr
+	// DNSServers 是接口上配置的 DNS 服务器
+	DNSServers []netip.Addr
+
+	// 各地址族的接口 metric，以及它是否由系统自动计算
+	metricV4          uint32
+	metricV6          uint32
+	automaticMetricV4 bool
+	automaticMetricV6 bool
+	// 各地址族是否在接口上启用（IP 接口表中存在该地址族的行）
+	ipv4Enabled bool
+	ipv6Enabled bool
+	// ipInfoUnknown 表示读取 IP 接口表失败，上面的 metric 与地址族信息都未知
+	ipInfoUnknown bool
+}
+
+// usesAutomaticMetric 判断接口在 addr 所属地址族上是否使用自动 metric。
+func (i *Interface) usesAutomaticMetric(addr netip.Addr) bool {
+	if addr.Is4() {
+		return i.automaticMetricV4
+	}
+	return i.automaticMetricV6
+}
+
+// metricFor 返回接口在 addr 所属地址族上的接口 metric。
+func (i *Interface) metricFor(addr netip.Addr) uint32 {
+	if addr.Is4() {
+		return i.metricV4
+	}
+	return i.metricV6
+}
+
+// interfaceMetric 返回接口 metric 及其是否自动计算，规则与 GetInterfaceMetric 相同：
+// 启用了 IPv4 时返回 IPv4 的设置，否则返回 IPv6 的设置（两个地址族都未启用时为零值）。
+func (i *Interface) interfaceMetric() (metric uint32, automatic bool) {
+	if i.ipv4Enabled {
+		return i.metricV4, i.automaticMetricV4
+	}
+	return i.metricV6, i.automaticMetricV6
+}
+
+// familyEnabled 判断接口是否启用了 addr 所属的地址族。信息未知时视为已启用。
+func (i *Interface) familyEnabled(addr netip.Addr) bool {
+	if i.ipInfoUnknown {
+		return true
+	}
+	if addr.Is4() {
+		return i.ipv4Enabled
+	}
+	return i.ipv6Enabled
+}
+
+// isSoftwareLoopback 判断接口是否是环回伪接口（Loopback Pseudo-Interface）。
+// 接口类型编码在 LUID 的高 16 位中，因此无需额外查询。
+func (i *Interface) isSoftwareLoopback() bool {
+	return winapi.IfType(uint64(i.LUID)>>48) == winapi.IfTypeSoftwareLoopback
+}
+
+// clone 返回接口信息的深拷贝，切片字段不与原接口共享底层数组。
+func (i *Interface) clone() *Interface {
+	c := *i
+	c.Addresses = slices.Clone(i.Addresses)
+	c.Gateways = slices.Clone(i.Gateways)
+	c.DNSServers = slices.Clone(i.DNSServers)
+	return &c
+}
+
+// TypeName 返回接口类型的可读名称，例如 "Ethernet"、"Wireless"、"PPP"、"Loopback" 或 "Tunnel"。
+// 不常见的类型显示为 "Type <编号>"（编号见 IANA ifType）。
+func (i *Interface) TypeName() string {
+	return iftype.Name(uint32(i.IfType))
+}
+
+// SpeedName 返回可读的链路速度，例如 "1 Gbps"；收发速度不同时显示为 "100 Mbps / 1 Gbps"（发送 / 接收）。
+func (i *Interface) SpeedName() string {
+	if i.TransmitSpeed == i.ReceiveSpeed {
+		return linkspeed.Format(i.TransmitSpeed)
+	}
+	return linkspeed.Format(i.TransmitSpeed) + " / " + linkspeed.Format(i.ReceiveSpeed)
+}
+
+// IsUp 判断接口是否处于运行状态。
+func (i *Interface) IsUp() bool {
+	return i.OperStatus == winapi.IfOperStatusUp
 }
 
 // Route 代表一条完整的、信息丰富的路由。
@@ -22,10 +127,159 @@ type Route struct {
 	NextHop     netip.Addr
 	Interface   *Interface // 路由所使用的接口
 	Metric      uint32
-	Protocol    winipcfg.RouteProtocol
-	Origin      winipcfg.RouteOrigin
+	Protocol    winapi.RouteProtocol
+	Origin      winapi.RouteOrigin
+
+	// 以下字段为只读信息，修改它们不会影响系统中的路由。
+	ValidLifetime     time.Duration // 路由的剩余有效期，永久路由为 InfiniteLifetime
+	PreferredLifetime time.Duration // 路由的剩余首选期，永久路由为 InfiniteLifetime
+	Age               time.Duration // 路由自添加（或最近一次被系统更新）以来经过的时间，精度为秒
+	// PreferredSource 是根据接口单播地址推断出的源地址，接口没有同族地址时为零值。
+	// 它是一个推断结果，系统实际的源地址选择可能会考虑更多规则。
+	PreferredSource netip.Addr
+	// AutomaticMetric 表示路由没有自己的 metric 偏移量（Metric 为 0），
+	// 且接口使用系统自动计算的 metric，即路由的实际优先级完全由系统自动决定。
+	AutomaticMetric bool
+	// Loopback 和 Publish 对应 MIB_IPFORWARD_ROW2 中的同名标志，见 RouteSpec。
+	Loopback bool
+	Publish  bool
+
+	raw *winapi.MibIPforwardRow2 // 仅在查询时传入 IncludeRawRow 才会设置
+}
+
+// RouteSpec 描述一条待添加的路由，供 AddRouteSpec 使用。
+type RouteSpec struct {
+	Destination    netip.Prefix
+	NextHop        netip.Addr
+	InterfaceIndex uint32
+	// Metric 是路由的 metric 偏移量。Windows 计算路由优先级时使用
+	// 接口 metric 与此值之和，值越小越优先。
+	// Metric 为 0 时路由不设置自己的偏移量，实际 metric 完全取自接口 metric。
+	Metric uint32
+
+	// ValidLifetime 是路由的有效期，到期后系统会自动删除该路由。
+	// 零值表示永不过期。
+	ValidLifetime time.Duration
+	// PreferredLifetime 是路由的首选期，必须不超过 ValidLifetime。
+	// 零值表示与 ValidLifetime 相同。
+	PreferredLifetime time.Duration
+
+	// PreferredSource 是希望该路由上的流量使用的源地址，零值表示不指定。
+	// 它必须是 InterfaceIndex 接口上与 Destination 同族的单播地址，否则添加失败。
+	// 注意：Windows 路由表不保存源地址，系统仍按自己的源地址选择规则（RFC 6724）为连接选择源地址，
+	// 因此这里只做校验，不能保证生效；需要强制时可以对接口上的其他地址设置 SkipAsSource。
+	PreferredSource netip.Addr
+
+	// 以下为高级选项，默认均为 false，一般无需设置。
+	// Loopback 表示该路由用于环回目标。
+	Loopback bool
+	// Publish 表示在路由器通告中发布该路由（例如用于邻居发现代理）。
+	Publish bool
+}
+
+// Raw 返回该路由对应的原始 MIB_IPFORWARD_ROW2 的副本。
+// 只有在查询时传入 IncludeRawRow 才可用，否则返回 nil。修改返回值不会影响系统中的路由。
+func (r *Route) Raw() *winapi.MibIPforwardRow2 {
+	return r.raw
+}
+
+// Clone 返回路由的深拷贝。与直接复制 Route 值不同，副本持有自己的 Interface 副本，
+// 而不是与查询结果共享的接口对象，因此适合保存快照用于之后的比较或回滚。
+// 通过 IncludeRawRow 附带的原始行同样会被复制。
+func (r *Route) Clone() *Route {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	if r.Interface != nil {
+		c.Interface = r.Interface.clone()
+	}
+	if r.raw != nil {
+		raw := *r.raw
+		c.raw = &raw
+	}
+	return &c
+}
+
+// String 将路由格式化为一行，例如：
+//
+//	10.0.0.0/8 via 192.168.1.1 dev Ethernet(5) metric 10 [NetMgmt]
+//
+// 直连路由（下一跳未指定）显示为 "on-link" 而不是 "via <下一跳>"。
+func (r *Route) String() string {
+	if r == nil {
+		return "<nil>"
+	}
+	var alias string
+	var index uint32
+	if r.Interface != nil {
+		alias, index = r.Interface.Alias, r.Interface.Index
+	}
+	return routefmt.Route(r.Destination, r.NextHop, alias, index, r.Metric, uint32(r.Protocol))
 }
 
 func (r *Route) Delete() error {
-	return r.Interface.LUID.DeleteRoute(r.Destination, r.NextHop)
+	err := provider.deleteRoute(r.Interface.LUID, r.Destination, r.NextHop)
+	logSyscall("DeleteIpForwardEntry2", err, "destination", r.Destination, "nextHop", r.NextHop, "index", r.Interface.Index)
+	return mapAccessDenied(err)
+}
+
+// CopyToInterface 在另一个接口上创建一条等价路由（相同的目标和下一跳），并使用给定的 metric。
+// 接口不存在时返回 ErrNotFound；路由已存在时的错误与 AddRoute 相同。
+// 下一跳上原有的 zone 指向原接口，复制时会被移除。
+func (r *Route) CopyToInterface(ifaceIndex uint32, metric uint32) error {
+	if _, err := FindInterfaceByIndex(ifaceIndex); err != nil {
+		return err
+	}
+	return AddRoute(r.Destination, r.NextHop.WithZone(""), ifaceIndex, metric)
+}
+
+// IsSystemRoute 判断该路由是否是 Windows 为自身用途安装的系统路由：
+// 环回（127.0.0.0/8、::1/128）、组播（224.0.0.0/4、ff00::/8）、
+// 广播（255.255.255.255/32 以及接口子网的定向广播地址）和链路本地（169.254.0.0/16、fe80::/10）。
+func (r *Route) IsSystemRoute() bool {
+	return routeclass.IsSystem(r.Destination, r.Interface.Addresses)
+}
+
+// IsLinkLocal 判断路由目标是否位于链路本地地址范围内（fe80::/10 或 169.254.0.0/16）。
+func (r *Route) IsLinkLocal() bool {
+	return routeclass.IsLinkLocal(r.Destination)
+}
+
+// IsUniqueLocal 判断路由目标是否位于 IPv6 唯一本地地址（ULA）范围 fc00::/7 内。
+func (r *Route) IsUniqueLocal() bool {
+	return routeclass.IsUniqueLocal(r.Destination)
+}
+
+// IsMulticast 判断路由目标是否位于组播地址范围内（ff00::/8 或 224.0.0.0/4）。
+func (r *Route) IsMulticast() bool {
+	return routeclass.IsMulticast(r.Destination)
+}
+
+// IsBlackhole 判断该路由是否是黑洞路由，即匹配的流量被丢弃而不会发往网络。
+//
+// Windows 没有专门的黑洞路由类型，常见做法是把路由指向环回伪接口
+// （例如 route add 203.0.113.0 mask 255.255.255.0 127.0.0.1，或 netsh 指定环回接口）。
+// 因此判定规则是：路由的出接口是环回伪接口（接口类型 IF_TYPE_SOFTWARE_LOOPBACK），
+// 且该路由不是系统路由（见 IsSystemRoute）——环回接口上的系统路由是 Windows 自身安装的环回、组播和广播路由。
+// 经由物理接口的路由即使下一跳为 0.0.0.0 也属于正常的直连（on-link）路由，不视为黑洞。
+func (r *Route) IsBlackhole() bool {
+	return routeclass.IsBlackhole(r.Destination, r.Interface.isSoftwareLoopback(), r.Interface.Addresses)
+}
+
+// effectiveMetric 返回 Windows 比较路由时使用的 metric：路由 Metric 与其地址族的接口 metric 之和，溢出时取最大值。
+func (r *Route) effectiveMetric() uint32 {
+	return uint32(min(uint64(r.Metric)+uint64(r.Interface.metricFor(r.Destination.Addr())), math.MaxUint32))
+}
+
+// Equal 判断两条路由是否相同：比较 Destination、NextHop、Interface.Index 和 Metric，
+// 不比较 Interface 指针本身及接口描述等其他信息。两个 nil 路由视为相等。
+func (r *Route) Equal(other *Route) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return r.Destination == other.Destination &&
+		r.NextHop == other.NextHop &&
+		r.Interface.Index == other.Interface.Index &&
+		r.Metric == other.Metric
 }
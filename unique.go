@@ -0,0 +1,72 @@
+package winroute
+
+import (
+	"errors"
+	"fmt"
+)
+
+// UniqueFilters 返回在 among 中只匹配 r 的最小过滤器组合，例如用于记录“以后如何删除这条路由”。
+//
+// 候选过滤器依次为 WithDestinationPrefix、WithInterfaceIndex、WithNextHop 和 WithMetric，
+// 取值都来自 r。先尝试单个过滤器，再尝试两个、三个的组合，同样大小的组合按候选顺序优先，
+// 因此通常得到目标前缀，或者目标前缀加接口索引。
+// among 中与 r 相等（见 Route.Equal）的路由视为 r 本身，因此 r 可以来自另一次查询。
+// 任何组合都无法排除其他路由时返回 ErrAmbiguousMatch。
+func UniqueFilters(r *Route, among []*Route) ([]FilterOption, error) {
+	if r == nil {
+		return nil, errors.New("route must not be nil")
+	}
+	candidates := []FilterOption{
+		WithDestinationPrefix(r.Destination),
+		WithInterfaceIndex(r.Interface.Index),
+		WithNextHop(r.NextHop),
+		WithMetric(r.Metric),
+	}
+
+	var others []*Route
+	for _, route := range among {
+		if !route.Equal(r) {
+			others = append(others, route)
+		}
+	}
+
+	for size := 1; size <= len(candidates); size++ {
+		for _, filters := range combinations(candidates, size) {
+			if !matchesAny(others, filters) {
+				return filters, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: no filter combination distinguishes %s", ErrAmbiguousMatch, r)
+}
+
+// matchesAny 判断 routes 中是否有路由匹配 filters 中的所有过滤器。
+func matchesAny(routes []*Route, filters []FilterOption) bool {
+	for _, route := range routes {
+		matched := true
+		for _, filter := range filters {
+			if !filter.match(route) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// combinations 按字典序返回 items 中所有包含 size 个元素的组合。
+func combinations[T any](items []T, size int) [][]T {
+	if size == 0 {
+		return [][]T{nil}
+	}
+	var result [][]T
+	for i := 0; i+size <= len(items); i++ {
+		for _, rest := range combinations(items[i+1:], size-1) {
+			result = append(result, append([]T{items[i]}, rest...))
+		}
+	}
+	return result
+}
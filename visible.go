@@ -0,0 +1,58 @@
+package winroute
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/bnkrr/winroute/internal/poll"
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// visibilityPollInterval 是等待路由可见时轮询路由表的间隔。
+const visibilityPollInterval = 50 * time.Millisecond
+
+// VisibilityWait 让增加路由的函数在系统调用成功后继续等待，直到新路由出现在
+// GetRoutes 的结果中，由 WaitForVisible 创建。
+type VisibilityWait struct {
+	timeout time.Duration
+}
+
+// WaitForVisible 创建一个选项：增加路由后轮询路由表，直到新路由可见或超过 timeout。
+// 超时后返回包装了 context.DeadlineExceeded 的错误；此时路由已经创建，只是尚未可见。
+// 可以传给 AddRoute、AddRouteSpec 和 AddRoutes。
+func WaitForVisible(timeout time.Duration) VisibilityWait {
+	return VisibilityWait{timeout: timeout}
+}
+
+// waitForRoute 轮询路由表，直到出现指定接口上目标和下一跳都匹配的路由。
+// cache 可以为 nil。
+func waitForRoute(cache *interfaceCache, destination netip.Prefix, nextHop netip.Addr, luid winapi.LUID, timeout time.Duration) error {
+	if cache == nil {
+		var err error
+		if cache, err = buildInterfaceCache(); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	filters := []FilterOption{WithDestinationPrefix(destination)}
+	err := poll.Until(ctx, visibilityPollInterval, func() (bool, error) {
+		visible := false
+		err := scanRoutes(cache, filters, func(r *Route) bool {
+			if r.Interface.LUID == luid && r.NextHop.WithZone("") == nextHop.WithZone("") {
+				visible = true
+				return false
+			}
+			return true
+		})
+		return visible, err
+	})
+	if err != nil {
+		return fmt.Errorf("route to %s was added but did not become visible within %s: %w", destination, timeout, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package winroute
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bnkrr/winroute/internal/winapi"
+)
+
+// ---- WatchInterfaces: 接口变化通知 ----
+
+// InterfaceChangeType 表示接口变化的类型。
+type InterfaceChangeType int
+
+const (
+	// InterfaceChanged 表示接口的参数发生变化，例如连接状态或 metric。
+	InterfaceChanged InterfaceChangeType = iota
+	// InterfaceAdded 表示接口在某个地址族上出现（例如网卡启用或插入）。
+	InterfaceAdded
+	// InterfaceRemoved 表示接口在某个地址族上消失（例如网卡禁用或拔出）。
+	InterfaceRemoved
+)
+
+// String 返回变化类型的简短名称。
+func (t InterfaceChangeType) String() string {
+	switch t {
+	case InterfaceChanged:
+		return "changed"
+	case InterfaceAdded:
+		return "added"
+	case InterfaceRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// InterfaceChangeEvent 描述一次接口变化。系统按地址族分别通知，
+// 同一个网卡的 IPv4 和 IPv6 变化会产生两个事件。
+type InterfaceChangeEvent struct {
+	Type   InterfaceChangeType
+	Index  uint32
+	LUID   winapi.LUID
+	Family winapi.AddressFamily
+	// 以下字段是变化后的接口状态，Type 为 InterfaceRemoved 时可能为零值。
+	Connected       bool   // 接口在该地址族上是否已连接
+	Metric          uint32 // 接口 metric
+	AutomaticMetric bool   // 接口 metric 是否由系统自动计算
+}
+
+// WatchInterfaces 订阅接口变化通知（NotifyIpInterfaceChange），在网卡启用、停用、连接状态或 metric
+// 改变时向返回的通道发送事件。路由是否可用取决于接口状态，监控程序通常需要同时关注这两类变化。
+//
+// ctx 结束时取消订阅并关闭通道。事件由系统通知线程异步产生，不保证严格按发生顺序送达；
+// 接收方处理过慢时，后续通知会等待接收方，而不会被丢弃。
+func WatchInterfaces(ctx context.Context) (<-chan InterfaceChangeEvent, error) {
+	events := make(chan InterfaceChangeEvent, 16)
+	unregister, err := provider.watchInterfaces(func(notificationType winapi.MibNotificationType, row *winapi.MibIPInterfaceRow) {
+		event := InterfaceChangeEvent{
+			Index:           row.InterfaceIndex,
+			LUID:            row.InterfaceLUID,
+			Family:          row.Family,
+			Connected:       row.Connected,
+			Metric:          row.Metric,
+			AutomaticMetric: row.UseAutomaticMetric,
+		}
+		switch notificationType {
+		case winapi.MibAddInstance:
+			event.Type = InterfaceAdded
+		case winapi.MibDeleteInstance:
+			event.Type = InterfaceRemoved
+		case winapi.MibParameterNotification:
+			event.Type = InterfaceChanged
+		default:
+			// 未请求初始通知，其他类型不会出现
+			return
+		}
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	})
+	logSyscall("NotifyIpInterfaceChange", err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register interface change notification: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		// Unregister 会等待进行中的回调返回，它们在 ctx 结束后不会再阻塞，之后才能安全地关闭通道。
+		err := unregister()
+		logSyscall("CancelMibChangeNotify2", err)
+		close(events)
+	}()
+	return events, nil
+}